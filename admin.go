@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+var campaignChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "custom-mint", Value: "customMint"},
+	{Name: "easy-mint", Value: "easyMint"},
+}
+
+var adminCommand = &discordgo.ApplicationCommand{
+	Name:        "admin",
+	Description: "Manage the campaigns, for server admins",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "artwork",
+			Description: "Set the artwork of a campaign",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "campaign",
+					Description: "The campaign to update",
+					Required:    true,
+					Choices:     campaignChoices,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file",
+					Description: "The new artwork",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "release",
+			Description: "Put the unminted pool item of a claimant back into the pool",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "campaign",
+					Description: "The campaign of the pool",
+					Required:    true,
+					Choices:     campaignChoices,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user_address",
+					Description: "The address the item was handed out to",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reveal",
+			Description: "Reveal the NFTs minted with placeholder metadata",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "campaign",
+					Description: "The campaign to reveal",
+					Required:    true,
+					Choices:     campaignChoices,
+				},
+			},
+		},
+	},
+}
+
+// adminHandlers are keyed by the subcommand of /admin.
+var adminHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate, options map[string]*discordgo.ApplicationCommandInteractionDataOption){
+	"artwork": handleAdminArtwork,
+	"release": handleAdminRelease,
+	"reveal":  handleAdminReveal,
+}
+
+// handleAdmin checks that the member may manage the server, or has one of the adminRoleIds, before running the
+// subcommand. Every answer is only visible to the admin.
+func handleAdmin(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	locale := i18n.InteractionLocale(i)
+	if !isAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: i18n.T(locale, "admin.not_allowed"),
+				Flags:   uint64(discordgo.MessageFlagsEphemeral),
+			},
+		})
+		return
+	}
+
+	sub := i.ApplicationCommandData().Options[0]
+	h, ok := adminHandlers[sub.Name]
+	if !ok {
+		return
+	}
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range sub.Options {
+		options[option.Name] = option
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: uint64(discordgo.MessageFlagsEphemeral),
+		},
+	})
+	h(s, i, options)
+}
+
+func isAdmin(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	if i.Member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0 {
+		return true
+	}
+	for _, role := range i.Member.Roles {
+		for _, adminRole := range viper.GetStringSlice("adminRoleIds") {
+			if role == adminRole {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func handleAdminArtwork(s *discordgo.Session, i *discordgo.InteractionCreate, options map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	locale := i18n.InteractionLocale(i)
+	campaign := options["campaign"].StringValue()
+	attachment := i.ApplicationCommandData().Resolved.Attachments[options["file"].Value.(string)]
+
+	url, err := uploadAttachment(attachment)
+	if err != nil {
+		adminFollowup(s, i, adminUploadError(s, i, locale, campaign, err))
+		return
+	}
+	if err = database.SetCampaignSetting(campaign+".fileUrl", url); err != nil {
+		adminFollowup(s, i, reportError(s, i, locale, campaign, err))
+		return
+	}
+	log.Printf("%s set the artwork of %s to %s", interactionUser(i).ID, campaign, url)
+
+	sendAdminLog(s, &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: "Campaign artwork changed",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Campaign", Value: campaign, Inline: true},
+			{Name: "By", Value: fmt.Sprintf("<@%s>", interactionUser(i).ID), Inline: true},
+			{Name: "File", Value: url},
+		},
+		Image: &discordgo.MessageEmbedImage{URL: url},
+	})
+	s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: i18n.T(locale, "admin.artwork.updated", campaign),
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Type:  discordgo.EmbedTypeRich,
+				URL:   url,
+				Image: &discordgo.MessageEmbedImage{URL: url},
+			},
+		},
+		Flags: uint64(discordgo.MessageFlagsEphemeral),
+	})
+}
+
+// uploadAttachment forwards a Discord attachment to NFTRainbow. The size and type Discord reports are checked before
+// downloading it, the upload checks them again on the content.
+func uploadAttachment(attachment *discordgo.MessageAttachment) (string, error) {
+	if attachment == nil {
+		return "", errors.New("the attachment is missing from the interaction")
+	}
+	if int64(attachment.Size) > service.MaxUploadSize() {
+		return "", fmt.Errorf("%w: %d bytes, at most %d", service.ErrFileTooLarge, attachment.Size, service.MaxUploadSize())
+	}
+	token, err := service.Login()
+	if err != nil {
+		return "", newClaimError(errUpstream, err)
+	}
+	return service.UploadURL(token, attachment.URL)
+}
+
+// adminUploadError explains limits the file broke, other errors are reported like failed claims.
+func adminUploadError(s *discordgo.Session, i *discordgo.InteractionCreate, locale discordgo.Locale, campaign string, err error) string {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		return i18n.T(locale, "admin.upload.too_large", service.MaxUploadSize()>>20)
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		return i18n.T(locale, "admin.upload.unsupported", err.Error())
+	default:
+		return reportError(s, i, locale, campaign, err)
+	}
+}
+
+func adminFollowup(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   uint64(discordgo.MessageFlagsEphemeral),
+	})
+}
+
+// handleAdminRelease returns the item of a failed claim to the pool. The claim is only retried with the same item, so
+// an item whose claimant does not come back would be lost otherwise. Minted items are never released.
+func handleAdminRelease(s *discordgo.Session, i *discordgo.InteractionCreate, options map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	locale := i18n.InteractionLocale(i)
+	campaign := options["campaign"].StringValue()
+	address, err := utils.NormalizeAddress(campaignChain(campaign), options["user_address"].StringValue())
+	if err != nil {
+		adminFollowup(s, i, i18n.T(locale, "error.invalid_address"))
+		return
+	}
+	released, err := releaseCampaignPoolItem(campaign, address)
+	if err != nil {
+		adminFollowup(s, i, reportError(s, i, locale, campaign, newClaimError(errInternal, err)))
+		return
+	}
+	if !released {
+		adminFollowup(s, i, i18n.T(locale, "admin.release.none", address, campaign))
+		return
+	}
+	log.Printf("%s released the pool item of %s in %s", interactionUser(i).ID, address, campaign)
+	adminFollowup(s, i, i18n.T(locale, "admin.release.done", address, campaign))
+}
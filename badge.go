@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // animated avatars
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/viper"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// badgeTemplate lays out the personalized badge of a campaign on its background.
+type badgeTemplate struct {
+	Background string      `mapstructure:"background"` // path or http(s) url of a png or jpeg
+	Font       string      `mapstructure:"font"`       // path or url of a TrueType font, defaults to Go Regular
+	Avatar     badgeAvatar `mapstructure:"avatar"`
+	Texts      []badgeText `mapstructure:"texts"`
+}
+
+type badgeAvatar struct {
+	X    int `mapstructure:"x"`
+	Y    int `mapstructure:"y"`
+	Size int `mapstructure:"size"` // 0 leaves the avatar out
+}
+
+// badgeText is drawn with its baseline starting at x, y. Text is a text/template with the claimant placeholders.
+type badgeText struct {
+	Text  string  `mapstructure:"text"`
+	X     int     `mapstructure:"x"`
+	Y     int     `mapstructure:"y"`
+	Size  float64 `mapstructure:"size"`
+	Color string  `mapstructure:"color"` // hex such as "#ffffff"
+}
+
+var (
+	badgeAssetsMu sync.Mutex
+	badgeAssets   = make(map[string][]byte)
+)
+
+// maxBadgeAssetSize bounds a downloaded background, font or avatar.
+const maxBadgeAssetSize = 10 << 20
+
+// badgeClient downloads the assets of the badges, a claim must not hang on a slow host.
+var badgeClient = &http.Client{Timeout: 15 * time.Second}
+
+// campaignBadge renders the badge of the claimant and uploads it to NFTRainbow, returning its url. It returns "" when
+// the campaign has no badge.
+func campaignBadge(token, campaign string, claim *claimant) (string, error) {
+	if !viper.GetBool(campaign + ".badge.enabled") {
+		return "", nil
+	}
+	var tmpl badgeTemplate
+	if err := viper.UnmarshalKey(campaign+".badge", &tmpl); err != nil {
+		return "", newClaimError(errInternal, fmt.Errorf("invalid %s.badge: %w", campaign, err))
+	}
+	img, err := tmpl.render(claim)
+	if err != nil {
+		return "", newClaimError(errInternal, err)
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return "", newClaimError(errInternal, err)
+	}
+	url, err := service.UploadReader(token, "badge-"+claim.UserID+".png", "image/png", &buf)
+	if err != nil {
+		return "", newClaimError(errUpstream, err)
+	}
+	if url == "" {
+		return "", newClaimError(errUpstream, fmt.Errorf("NFTRainbow returned no url for the badge of %s", claim.UserID))
+	}
+	return url, nil
+}
+
+func (t badgeTemplate) render(claim *claimant) (*image.RGBA, error) {
+	background, err := decodeBadgeImage(t.Background, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the badge background: %w", err)
+	}
+	img := image.NewRGBA(background.Bounds())
+	draw.Draw(img, img.Bounds(), background, background.Bounds().Min, draw.Src)
+
+	if t.Avatar.Size > 0 && claim.AvatarURL != "" {
+		// a missing avatar should not fail the claim
+		if avatar, err := decodeBadgeImage(claim.AvatarURL, false); err == nil {
+			rect := image.Rect(t.Avatar.X, t.Avatar.Y, t.Avatar.X+t.Avatar.Size, t.Avatar.Y+t.Avatar.Size)
+			scaled := image.NewRGBA(image.Rect(0, 0, t.Avatar.Size, t.Avatar.Size))
+			draw.CatmullRom.Scale(scaled, scaled.Bounds(), avatar, avatar.Bounds(), draw.Src, nil)
+			draw.DrawMask(img, rect, scaled, image.Point{}, circleMask(t.Avatar.Size), image.Point{}, draw.Over)
+		}
+	}
+
+	fontData := goregular.TTF
+	if t.Font != "" {
+		if fontData, err = loadBadgeAsset(t.Font, true); err != nil {
+			return nil, fmt.Errorf("failed to load the badge font: %w", err)
+		}
+	}
+	parsed, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid badge font: %w", err)
+	}
+	for _, text := range t.Texts {
+		content, err := renderBadgeText(text.Text, claim)
+		if err != nil {
+			return nil, err
+		}
+		if content == "" {
+			continue
+		}
+		size := text.Size
+		if size <= 0 {
+			size = 24
+		}
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		textColor := color.Color(color.White)
+		if text.Color != "" {
+			rgb := parseColor(text.Color)
+			textColor = color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+		}
+		drawer := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(textColor),
+			Face: face,
+			Dot:  fixed.P(text.X, text.Y),
+		}
+		drawer.DrawString(content)
+		face.Close()
+	}
+	return img, nil
+}
+
+func renderBadgeText(text string, claim *claimant) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("badge").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid badge text %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, claim); err != nil {
+		return "", fmt.Errorf("failed to render badge text %q: %w", text, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func decodeBadgeImage(source string, cache bool) (image.Image, error) {
+	data, err := loadBadgeAsset(source, cache)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// loadBadgeAsset reads a file or downloads a url. Downloaded campaign assets are kept in memory, avatars are not.
+func loadBadgeAsset(source string, cache bool) ([]byte, error) {
+	remote := strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+	cache = cache && remote
+	if cache {
+		badgeAssetsMu.Lock()
+		data, ok := badgeAssets[source]
+		badgeAssetsMu.Unlock()
+		if ok {
+			return data, nil
+		}
+	}
+
+	var data []byte
+	var err error
+	if remote {
+		var resp *http.Response
+		resp, err = badgeClient.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", source, resp.Status)
+		}
+		data, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxBadgeAssetSize+1))
+		if err == nil && len(data) > maxBadgeAssetSize {
+			return nil, fmt.Errorf("GET %s: more than %d bytes", source, maxBadgeAssetSize)
+		}
+	} else {
+		data, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cache {
+		badgeAssetsMu.Lock()
+		badgeAssets[source] = data
+		badgeAssetsMu.Unlock()
+	}
+	return data, nil
+}
+
+// circleMask is the mask of a round avatar of the size.
+type circleMask int
+
+func (c circleMask) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (c circleMask) Bounds() image.Rectangle {
+	return image.Rect(0, 0, int(c), int(c))
+}
+
+func (c circleMask) At(x, y int) color.Color {
+	r := float64(c) / 2
+	dx, dy := float64(x)+0.5-r, float64(y)+0.5-r
+	if dx*dx+dy*dy <= r*r {
+		return color.Alpha{A: 0xff}
+	}
+	return color.Alpha{}
+}
@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/cobra"
+)
+
+var collectionImageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true}
+
+var collectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "prepare collections of pre-made NFTs",
+}
+
+var collectionPrepareCmd = &cobra.Command{
+	Use:   "prepare",
+	Short: "upload a folder of images and create the metadata of each",
+	Long: `Uploads every image of the folder, creates the metadata of each from the optional sidecar file and writes the manifest the bot hands the NFTs out from.
+The sidecar is metadata.json or metadata.csv in the folder unless --sidecar is given. The JSON maps the item id, which is the file name without extension and must be unique in the folder, to its name, description, external_url, animation_url, background_color and attributes. The CSV has a file (or id) column, name and description columns, and every other column is a trait.
+The manifest is saved after each item, running the command again resumes where it failed and only redoes the items whose file or metadata changed.`,
+	Example: `botCMD collection prepare [dir]
+- dir The folder of the images
+- --name The collection name, items without a name are called "<name> #<id>". Defaults to the name of the manifest or the folder
+- --description The description of the items without one
+- --sidecar The JSON or CSV file of the item metadata
+- --manifest Where to write the manifest, defaults to manifest.json in the folder`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := args[0]
+		name, _ := cmd.Flags().GetString("name")
+		description, _ := cmd.Flags().GetString("description")
+		sidecarPath, _ := cmd.Flags().GetString("sidecar")
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		if manifestPath == "" {
+			manifestPath = filepath.Join(dir, "manifest.json")
+		}
+
+		files, err := collectionImages(dir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		sidecar, err := loadSidecar(dir, sidecarPath)
+		if err != nil {
+			fmt.Println(fmt.Errorf("invalid sidecar: %w", err))
+			return
+		}
+		manifest, err := loadManifest(manifestPath)
+		if err != nil {
+			fmt.Println(fmt.Errorf("invalid manifest %s: %w", manifestPath, err))
+			return
+		}
+		// a resumed run keeps the name of the first one
+		if name == "" {
+			name = manifest.Name
+		}
+		if name == "" {
+			name = filepath.Base(filepath.Clean(dir))
+		}
+		manifest.Name = name
+		cache, err := loadUploadCache()
+		if err != nil {
+			fmt.Println(fmt.Errorf("invalid upload cache %s: %w", uploadCachePath(), err))
+			return
+		}
+		token, err := service.Login()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		previous := make(map[string]*models.CollectionItem)
+		for _, item := range manifest.Items {
+			previous[item.ID] = item
+		}
+		manifest.Items = nil
+		failed := 0
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tSTATUS\tFILE_URL\tMETADATA_URI")
+		for _, file := range files {
+			id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			item := previous[id]
+			if item == nil {
+				item = &models.CollectionItem{ID: id}
+			}
+			item.File = file
+			manifest.Items = append(manifest.Items, item)
+
+			status, err := prepareCollectionItem(token, item, sidecar[id], name, description, cache)
+			if err != nil {
+				failed++
+				item.Error = err.Error()
+				fmt.Fprintf(table, "%s\tfailed\t%s\t%v\n", id, orDash(item.FileUrl), err)
+			} else {
+				item.Error = ""
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", id, status, item.FileUrl, item.MetadataUri)
+			}
+			if err = saveManifest(manifestPath, manifest); err != nil {
+				fmt.Println(fmt.Errorf("failed to save the manifest: %w", err))
+				return
+			}
+		}
+		table.Flush()
+
+		fmt.Printf("%d of %d items ready, manifest written to %s\n", len(files)-failed, len(files), manifestPath)
+		if failed > 0 {
+			fmt.Println("Run the command again to retry the failed items")
+		}
+	},
+}
+
+// sidecarItem is the metadata of an item given in the sidecar file.
+type sidecarItem struct {
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	ExternalUrl     string             `json:"external_url"`
+	AnimationUrl    string             `json:"animation_url"`
+	BackgroundColor string             `json:"background_color"`
+	Attributes      []sidecarAttribute `json:"attributes"`
+}
+
+type sidecarAttribute struct {
+	TraitType   string      `json:"trait_type"`
+	DisplayType string      `json:"display_type"`
+	Value       interface{} `json:"value"` // numbers are allowed as in the OpenSea metadata
+}
+
+// prepareCollectionItem uploads the file unless it is unchanged and creates the metadata unless it is unchanged.
+// It returns what was done.
+func prepareCollectionItem(token string, item *models.CollectionItem, meta *sidecarItem, collection, description string, cache uploadCache) (string, error) {
+	if meta == nil {
+		meta = &sidecarItem{}
+	}
+	hash, err := fileSHA256(item.File)
+	if err != nil {
+		return "", err
+	}
+
+	status := "unchanged"
+	if item.FileUrl == "" || item.SHA256 != hash {
+		if entry, ok := cache.get(hash); ok {
+			item.FileUrl = entry.FileUrl
+		} else {
+			res, err := service.UploadFileInfo(token, item.File)
+			if err != nil {
+				return "", err
+			}
+			cache.put(hash, &uploadCacheEntry{UploadFilesResponse: *res, UploadedAt: time.Now()})
+			if err = cache.save(); err != nil {
+				fmt.Println(fmt.Errorf("failed to save the upload cache: %w", err))
+			}
+			item.FileUrl = res.FileUrl
+		}
+		item.SHA256 = hash
+		status = "uploaded"
+	}
+
+	metadata := models.Metadata{
+		Name:            meta.Name,
+		Description:     meta.Description,
+		Image:           item.FileUrl,
+		ExternalLink:    meta.ExternalUrl,
+		AnimationUrl:    meta.AnimationUrl,
+		BackgroundColor: meta.BackgroundColor,
+	}
+	for _, attribute := range meta.Attributes {
+		metadata.Attributes = append(metadata.Attributes, models.Attributes{
+			TraitType:   attribute.TraitType,
+			DisplayType: attribute.DisplayType,
+			Value:       fmt.Sprint(attribute.Value),
+		})
+	}
+	if metadata.Name == "" {
+		metadata.Name = fmt.Sprintf("%s #%s", collection, item.ID)
+	}
+	if metadata.Description == "" {
+		metadata.Description = description
+	}
+	if metadata.Description == "" {
+		metadata.Description = metadata.Name
+	}
+	if err = service.ValidateMetadata(&metadata); err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	metadataHash := hex.EncodeToString(sum[:])
+
+	item.Name = metadata.Name
+	item.Traits = nil
+	for _, attribute := range metadata.Attributes {
+		item.Traits = append(item.Traits, &models.Trait{TraitType: attribute.TraitType, Value: attribute.Value})
+	}
+	if item.MetadataUri != "" && item.MetadataHash == metadataHash {
+		return status, nil
+	}
+	item.MetadataUri, err = service.CreateMetadata(token, metadata)
+	if err == nil && item.MetadataUri == "" {
+		err = fmt.Errorf("NFTRainbow returned no metadata uri")
+	}
+	if err != nil {
+		item.MetadataUri = ""
+		return "", err
+	}
+	item.MetadataHash = metadataHash
+	if status == "unchanged" {
+		status = "metadata updated"
+	} else {
+		status = "created"
+	}
+	return status, nil
+}
+
+// collectionImages returns the images at the top of the folder, sorted by name. Two images whose names only differ
+// in the extension, e.g. a.png and a.jpg, would share their item id, so they are refused.
+func collectionImages(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	ids := make(map[string]string)
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() && collectionImageExts[strings.ToLower(filepath.Ext(entry.Name()))] {
+			id := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			if other, ok := ids[id]; ok {
+				return nil, fmt.Errorf("%s and %s have the same item id %s, rename one of them", other, entry.Name(), id)
+			}
+			ids[id] = entry.Name()
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no images in %s", dir)
+	}
+	return files, nil
+}
+
+// loadSidecar reads the sidecar into items keyed by id. Without a path it looks for metadata.json and metadata.csv
+// in the folder, which are both optional.
+func loadSidecar(dir, path string) (map[string]*sidecarItem, error) {
+	if path == "" {
+		for _, name := range []string{"metadata.json", "metadata.csv"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				path = filepath.Join(dir, name)
+				break
+			}
+		}
+	}
+	if path == "" {
+		return map[string]*sidecarItem{}, nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return loadCSVSidecar(path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]*sidecarItem
+	if err = json.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	items := make(map[string]*sidecarItem)
+	for key, item := range raw {
+		// the key may be the file name as well
+		items[strings.TrimSuffix(key, filepath.Ext(key))] = item
+	}
+	return items, nil
+}
+
+func loadCSVSidecar(path string) (map[string]*sidecarItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return map[string]*sidecarItem{}, nil
+	}
+
+	header := records[0]
+	idColumn := -1
+	for i, column := range header {
+		if column == "file" || column == "id" {
+			idColumn = i
+		}
+	}
+	if idColumn < 0 {
+		return nil, fmt.Errorf("%s has no file or id column", path)
+	}
+	items := make(map[string]*sidecarItem)
+	for _, record := range records[1:] {
+		item := &sidecarItem{}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch {
+			case i == idColumn || i >= len(header):
+			case header[i] == "name":
+				item.Name = value
+			case header[i] == "description":
+				item.Description = value
+			case header[i] == "external_url":
+				item.ExternalUrl = value
+			case header[i] == "animation_url":
+				item.AnimationUrl = value
+			case header[i] == "background_color":
+				item.BackgroundColor = value
+			case value != "":
+				item.Attributes = append(item.Attributes, sidecarAttribute{TraitType: header[i], Value: value})
+			}
+		}
+		id := record[idColumn]
+		items[strings.TrimSuffix(id, filepath.Ext(id))] = item
+	}
+	return items, nil
+}
+
+func loadManifest(path string) (*models.CollectionManifest, error) {
+	manifest := &models.CollectionManifest{}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func saveManifest(path string, manifest *models.CollectionManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(path+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func orDash(text string) string {
+	if text == "" {
+		return "-"
+	}
+	return text
+}
+
+func init() {
+	collectionPrepareCmd.Flags().String("name", "", "the collection name, defaults to the folder name")
+	collectionPrepareCmd.Flags().String("description", "", "the description of the items without one")
+	collectionPrepareCmd.Flags().String("sidecar", "", "the JSON or CSV file of the item metadata")
+	collectionPrepareCmd.Flags().String("manifest", "", "the manifest to write, defaults to manifest.json in the folder")
+	collectionCmd.AddCommand(collectionPrepareCmd)
+	rootCmd.AddCommand(collectionCmd)
+}
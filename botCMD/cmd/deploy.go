@@ -20,6 +20,7 @@ import (
 	"github.com/nft-rainbow/discordBot/service"
 	"github.com/nft-rainbow/discordBot/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var deployCmd = &cobra.Command{
@@ -30,11 +31,16 @@ var deployCmd = &cobra.Command{
 - name The name of the contract
 - symbol The symbol of the NFT
 - type The type of the contract including erc721 and erc1155
-- appAddress The address of the NFTRainbow app`,
+- appAddress The address of the NFTRainbow app
+- --chain The chain to deploy on, defaults to chainType in the config`,
 	Args: cobra.ExactArgs(4),
 	Run: func(cmd *cobra.Command, args []string) {
 		name, symbol, address, contractType := args[0], args[1], args[3], args[2]
-		_, err := utils.CheckCfxAddress(utils.CONFLUX_TEST, address)
+		chain, _ := cmd.Flags().GetString("chain")
+		if chain == "" {
+			chain = viper.GetString("chainType")
+		}
+		address, err := utils.NormalizeAddress(chain, address)
 		if err != nil {
 			fmt.Println(err)
 			return
@@ -46,7 +52,7 @@ var deployCmd = &cobra.Command{
 			return
 		}
 
-		contractAddress, err := service.DeployContract(token, name, symbol, address, contractType)
+		contractAddress, err := service.DeployContract(token, chain, name, symbol, address, contractType)
 		if err != nil {
 			fmt.Println(err)
 			return
@@ -56,5 +62,6 @@ var deployCmd = &cobra.Command{
 }
 
 func init() {
+	deployCmd.Flags().String("chain", "", "the chain to deploy on, e.g. conflux, conflux_test, conflux_espace or conflux_espace_test")
 	rootCmd.AddCommand(deployCmd)
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/reveal"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var revealCmd = &cobra.Command{
+	Use:   "reveal",
+	Short: "reveal the NFTs of a campaign minted with placeholder metadata",
+	Long: `Updates the placeholder metadata of every token the bot minted for the campaign to its final metadata, and marks the campaign revealed so new claims get the final metadata.
+It works on the database of the bot, which must not be running: use /admin reveal while it is. Tokens which fail are kept for the next run.
+With botToken in the config, the reveal is announced in --channel and each holder gets a DM.`,
+	Example: `botCMD reveal [campaign]
+- campaign The campaign to reveal, e.g. customMint
+- --db The database of the bot, defaults to ../bolt.db
+- --channel The Discord channel to announce the reveal in
+- --name The campaign name in the announcement, defaults to the campaign`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		campaign := args[0]
+		dbPath, _ := cmd.Flags().GetString("db")
+		channelID, _ := cmd.Flags().GetString("channel")
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = campaign
+		}
+
+		if err := database.OpenDB(dbPath); err != nil {
+			fmt.Println(err, "(is the bot running? use /admin reveal then)")
+			return
+		}
+		if revealed, _ := database.GetCampaignSetting(campaign + ".revealed"); revealed == "" {
+			if err := database.SetCampaignSetting(campaign+".revealed", time.Now().UTC().Format(time.RFC3339)); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		token, err := service.Login()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		result, err := reveal.Run(token, campaign)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, entry := range result.Failed {
+			fmt.Printf("token %s: %s\n", entry.TokenID, entry.Error)
+		}
+		fmt.Printf("%d revealed, %d failed, %d revealed before\n", len(result.Revealed), len(result.Failed), result.Skipped)
+		if result.Pending > 0 {
+			fmt.Printf("%d claims were still minting, run it again once they are done to reveal them too\n", result.Pending)
+		}
+
+		if len(result.Revealed) == 0 {
+			return
+		}
+		if viper.GetString("botToken") == "" {
+			fmt.Println("botToken is not in the config, the reveal is not announced")
+			return
+		}
+		s, err := discordgo.New("Bot " + viper.GetString("botToken"))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		reveal.Announce(s, channelID, name, result.Revealed)
+	},
+}
+
+func init() {
+	revealCmd.Flags().String("db", "../bolt.db", "the database of the bot")
+	revealCmd.Flags().String("channel", "", "the Discord channel to announce the reveal in")
+	revealCmd.Flags().String("name", "", "the campaign name in the announcement")
+	rootCmd.AddCommand(revealCmd)
+}
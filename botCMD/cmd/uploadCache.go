@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/spf13/viper"
+)
+
+// uploadCacheEntry is an uploaded file, keyed in the cache by the NFTRainbow host it was uploaded to and the SHA-256
+// of its content, so switching between the test and the main host uploads the files again.
+type uploadCacheEntry struct {
+	models.UploadFilesResponse
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+type uploadCache map[string]*uploadCacheEntry
+
+// uploadCachePath is uploadCache in the config, upload-cache.json in the working directory by default.
+func uploadCachePath() string {
+	if path := viper.GetString("uploadCache"); path != "" {
+		return path
+	}
+	return "upload-cache.json"
+}
+
+func loadUploadCache() (uploadCache, error) {
+	cache := make(uploadCache)
+	content, err := ioutil.ReadFile(uploadCachePath())
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, &cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func uploadCacheKey(hash string) string {
+	return viper.GetString("host") + " " + hash
+}
+
+func (c uploadCache) get(hash string) (*uploadCacheEntry, bool) {
+	entry, ok := c[uploadCacheKey(hash)]
+	return entry, ok
+}
+
+func (c uploadCache) put(hash string, entry *uploadCacheEntry) {
+	c[uploadCacheKey(hash)] = entry
+}
+
+// save writes the cache to a temporary file first, so an interrupted upload run cannot corrupt it.
+func (c uploadCache) save() error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := uploadCachePath()
+	if err = ioutil.WriteFile(path+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
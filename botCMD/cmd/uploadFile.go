@@ -17,33 +17,111 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
 	"github.com/nft-rainbow/discordBot/service"
 	"github.com/spf13/cobra"
 )
 
 var uploadFileCmd = &cobra.Command{
 	Use:   "upload",
-	Short: "upload your file to obtain the file_url",
-	Long: `In order to config the mint service in discord, the admin of the bot can choose to upload its own file to NFTRainbow server to obtain the file_url through this cmd.`,
-	Example: `botCMD upload [file_path]
-- file_path The path of the uploaded file`,
-	Args: cobra.ExactArgs(1),
+	Short: "upload your files to obtain the file_url",
+	Long: `In order to config the mint service in discord, the admin of the bot can choose to upload its own file to NFTRainbow server to obtain the file_url through this cmd.
+Uploaded files are recorded by the SHA-256 of their content, an unchanged file returns its cached file_url instead of being uploaded again.`,
+	Example: `botCMD upload [file_path...]
+- file_path The path of an uploaded file, or of a directory whose files are all uploaded
+- --force Upload the files even if they were uploaded before`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		token, err := service.Login()
+		force, _ := cmd.Flags().GetBool("force")
+		files, err := collectUploadFiles(args)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
-		fileUrl, err := service.UploadFile(token, args[0])
+		cache, err := loadUploadCache()
 		if err != nil {
-			fmt.Println(err)
+			fmt.Println(fmt.Errorf("invalid upload cache %s: %w", uploadCachePath(), err))
 			return
 		}
 
-		fmt.Println(fileUrl)
+		token := ""
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "FILE\tSTATUS\tSIZE\tTYPE\tFILE_URL")
+		for _, file := range files {
+			hash, err := fileSHA256(file)
+			if err != nil {
+				fmt.Fprintf(table, "%s\tfailed\t-\t-\t%v\n", file, err)
+				continue
+			}
+			status := "cached"
+			entry, ok := cache.get(hash)
+			if !ok || force {
+				if token == "" {
+					if token, err = service.Login(); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+				res, err := service.UploadFileInfo(token, file)
+				if err != nil {
+					fmt.Fprintf(table, "%s\tfailed\t-\t-\t%v\n", file, err)
+					continue
+				}
+				status = "uploaded"
+				entry = &uploadCacheEntry{UploadFilesResponse: *res, UploadedAt: time.Now()}
+				cache.put(hash, entry)
+				// saved after every upload, so the files uploaded before a failure are not uploaded again
+				if err = cache.save(); err != nil {
+					fmt.Println(fmt.Errorf("failed to save the upload cache: %w", err))
+				}
+			}
+			fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%s\n", file, status, entry.FileSize, entry.FileType, entry.FileUrl)
+		}
+		table.Flush()
 	},
 }
 
+// collectUploadFiles expands the directories of the arguments to the files they contain, hidden ones excepted.
+func collectUploadFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			hidden := strings.HasPrefix(info.Name(), ".") && path != arg
+			if info.IsDir() {
+				if hidden {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !hidden && info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
 func init() {
+	uploadFileCmd.Flags().Bool("force", false, "upload the files even if they are in the upload cache")
 	rootCmd.AddCommand(uploadFileCmd)
 }
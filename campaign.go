@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/spf13/viper"
+)
+
+// campaignString reads "<campaign>.<key>" and falls back to the global "<key>" when it is empty, so that a campaign
+// can override bot wide settings.
+func campaignString(campaign, key string) string {
+	if campaign != "" {
+		if val := viper.GetString(campaign + "." + key); val != "" {
+			return val
+		}
+	}
+	return viper.GetString(key)
+}
+
+// campaignChain is the chain the campaign mints on, which also decides how addresses are validated.
+func campaignChain(campaign string) string {
+	return campaignString(campaign, "chainType")
+}
+
+// campaignFileURL is the artwork of the campaign. Artwork set by an admin from Discord takes precedence over the config.
+func campaignFileURL(campaign string) string {
+	if url, err := database.GetCampaignSetting(campaign + ".fileUrl"); err == nil && url != "" {
+		return url
+	}
+	return viper.GetString(campaign + ".fileUrl")
+}
+
+// campaignClosed tells whether the campaign is closed in the config or was closed by the bot, e.g. when its pool ran out.
+func campaignClosed(campaign string) bool {
+	if closed, err := database.GetCampaignSetting(campaign + ".closed"); err == nil && closed != "" {
+		return true
+	}
+	return viper.GetBool(campaign + ".closed")
+}
@@ -0,0 +1,47 @@
+package chain
+
+import (
+	"fmt"
+	"sync"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+var defaultRpcUrl = map[string]string{
+	utils.CONFLUX:      "https://main.confluxrpc.com",
+	utils.CONFLUX_TEST: "https://test.confluxrpc.com",
+}
+
+var (
+	clients   = make(map[string]*sdk.Client)
+	clientsMu sync.Mutex
+)
+
+// NewClient returns the RPC client of a conflux chain. "rpc.<chain>" in the config overrides the public node, e.g. to
+// point it to a local node or a mock JSON-RPC server. Clients are shared between callers.
+func NewClient(chain string) (*sdk.Client, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := clients[chain]; ok {
+		return client, nil
+	}
+	chainType, _, err := utils.ChainInfoByName(chain)
+	if err != nil {
+		return nil, err
+	}
+	if chainType != utils.CHAIN_TYPE_CFX {
+		return nil, fmt.Errorf("rpc of chain %s is not supported", chain)
+	}
+	url := viper.GetString("rpc." + chain)
+	if url == "" {
+		url = defaultRpcUrl[chain]
+	}
+	client, err := sdk.NewClient(url)
+	if err != nil {
+		return nil, err
+	}
+	clients[chain] = client
+	return client, nil
+}
@@ -0,0 +1,36 @@
+package chain
+
+import (
+	"math/big"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+)
+
+// LatestConfirmedEpoch is the newest epoch which will not be reverted, so logs up to it are final.
+func LatestConfirmedEpoch(client *sdk.Client) (uint64, error) {
+	epoch, err := client.GetEpochNumber(types.EpochLatestConfirmed)
+	if err != nil {
+		return 0, err
+	}
+	return epoch.ToInt().Uint64(), nil
+}
+
+// GetTransferLogs returns the Transfer and TransferSingle logs of the contracts between the epochs, both included.
+func GetTransferLogs(client *sdk.Client, contracts []string, from, to uint64) ([]types.Log, error) {
+	addresses := make([]types.Address, 0, len(contracts))
+	for _, contract := range contracts {
+		addr, err := cfxaddress.NewFromBase32(contract)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	return client.GetLogs(types.LogFilter{
+		FromEpoch: types.NewEpochNumber(types.NewBigIntByRaw(new(big.Int).SetUint64(from))),
+		ToEpoch:   types.NewEpochNumber(types.NewBigIntByRaw(new(big.Int).SetUint64(to))),
+		Address:   addresses,
+		Topics:    [][]types.Hash{{types.Hash(TransferTopic.Hex()), types.Hash(TransferSingleTopic.Hex())}},
+	})
+}
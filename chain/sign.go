@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VerifySignature tells whether the personal_sign signature of the message was made by the key of the address. A
+// base32 address is a Conflux core space account, which signs with the Conflux prefix in Fluent and whose hex
+// address starts with 0x1; a hex address is an EVM account signing with the Ethereum prefix.
+func VerifySignature(address, message, signature string) (bool, error) {
+	expected, err := CommonAddress(address)
+	if err != nil {
+		return false, err
+	}
+	sig, err := hexutil.Decode(strings.TrimSpace(signature))
+	if err != nil || len(sig) != 65 {
+		return false, fmt.Errorf("invalid signature %q", signature)
+	}
+	sig = append([]byte{}, sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	core := !common.IsHexAddress(address)
+	prefix := "\x19Ethereum Signed Message:\n"
+	if core {
+		prefix = "\x19Conflux Signed Message:\n"
+	}
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("%s%d%s", prefix, len(message), message)))
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return false, fmt.Errorf("invalid signature: %w", err)
+	}
+	signer := crypto.PubkeyToAddress(*pub)
+	if core {
+		signer[0] = signer[0]&0x0f | 0x10
+	}
+	return signer == expected, nil
+}
+
+var (
+	// ErrProofExpired means the proof message was given out longer ago than its proof may take.
+	ErrProofExpired = errors.New("the proof message expired")
+	// ErrWrongSigner means the signature is not the one of the address for the proof message, e.g. the message of
+	// an older nonce was signed.
+	ErrWrongSigner = errors.New("the proof message was not signed by the address")
+)
+
+// ProofMessage is the message the wallet of the address signs to prove it belongs to the Discord user. Its random
+// nonce keeps a signature from being replayed for another proof.
+func ProofMessage(address, userID string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Link the wallet %s to the Discord account %s.\nNonce: %s", address, userID, hex.EncodeToString(nonce)), nil
+}
+
+// VerifyProof checks the signature of the proof message given out at issuedAt, which is valid for ttl.
+func VerifyProof(address, message, signature string, issuedAt time.Time, ttl time.Duration) error {
+	if time.Since(issuedAt) > ttl {
+		return ErrProofExpired
+	}
+	ok, err := VerifySignature(address, message, signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWrongSigner
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func personalSign(t *testing.T, key *ecdsa.PrivateKey, prefix, message string) string {
+	t.Helper()
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("%s%d%s", prefix, len(message), message)))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig)
+}
+
+// testAccounts returns the key with its eSpace hex address and its core space base32 address.
+func testAccounts(t *testing.T) (*ecdsa.PrivateKey, string, string) {
+	t.Helper()
+	key, err := crypto.HexToECDSA(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexAddress := crypto.PubkeyToAddress(key.PublicKey)
+	coreAddress := hexAddress
+	coreAddress[0] = coreAddress[0]&0x0f | 0x10
+	base32, err := cfxaddress.NewFromCommon(coreAddress, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, hexAddress.Hex(), base32.String()
+}
+
+const (
+	ethPrefix = "\x19Ethereum Signed Message:\n"
+	cfxPrefix = "\x19Conflux Signed Message:\n"
+)
+
+func TestVerifySignature(t *testing.T) {
+	key, hexAddress, base32Address := testAccounts(t)
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := "Link the wallet to the Discord account 1.\nNonce: 00"
+	tests := []struct {
+		name    string
+		address string
+		sig     string
+		want    bool
+	}{
+		{"ethereum prefix", hexAddress, personalSign(t, key, ethPrefix, message), true},
+		{"conflux prefix", base32Address, personalSign(t, key, cfxPrefix, message), true},
+		{"hex address signed with the conflux prefix", hexAddress, personalSign(t, key, cfxPrefix, message), false},
+		{"base32 address signed with the ethereum prefix", base32Address, personalSign(t, key, ethPrefix, message), false},
+		{"other message", hexAddress, personalSign(t, key, ethPrefix, message+"1"), false},
+		{"other key", hexAddress, personalSign(t, other, ethPrefix, message), false},
+		{"other key conflux", base32Address, personalSign(t, other, cfxPrefix, message), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifySignature(tt.address, message, tt.sig)
+			if err != nil {
+				t.Fatalf("VerifySignature error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifySignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureMalformed(t *testing.T) {
+	_, hexAddress, _ := testAccounts(t)
+	for _, sig := range []string{"", "0x", "not hex", "0x1234"} {
+		if _, err := VerifySignature(hexAddress, "message", sig); err == nil {
+			t.Errorf("VerifySignature accepted the signature %q", sig)
+		}
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	key, hexAddress, base32Address := testAccounts(t)
+	ttl := 15 * time.Minute
+	hexMessage, err := ProofMessage(hexAddress, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base32Message, err := ProofMessage(base32Address, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a signature of an earlier proof message of the same wallet
+	staleMessage, err := ProofMessage(hexAddress, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staleMessage == hexMessage {
+		t.Fatal("ProofMessage repeated its nonce")
+	}
+	tests := []struct {
+		name     string
+		address  string
+		message  string
+		sig      string
+		issuedAt time.Time
+		err      error
+	}{
+		{"ethereum", hexAddress, hexMessage, personalSign(t, key, ethPrefix, hexMessage), time.Now(), nil},
+		{"conflux", base32Address, base32Message, personalSign(t, key, cfxPrefix, base32Message), time.Now(), nil},
+		{"wrong nonce", hexAddress, hexMessage, personalSign(t, key, ethPrefix, staleMessage), time.Now(), ErrWrongSigner},
+		{"expired nonce", hexAddress, hexMessage, personalSign(t, key, ethPrefix, hexMessage), time.Now().Add(-ttl - time.Minute), ErrProofExpired},
+		{"expired nonce conflux", base32Address, base32Message, personalSign(t, key, cfxPrefix, base32Message), time.Now().Add(-ttl - time.Minute), ErrProofExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyProof(tt.address, tt.message, tt.sig, tt.issuedAt, ttl)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("VerifyProof error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package chain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	sdkutils "github.com/Conflux-Chain/go-conflux-sdk/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrReverted means the contract reverted the call, e.g. ownerOf of a burned or nonexistent token. Other errors of
+// a call are RPC or transport errors.
+var ErrReverted = errors.New("call reverted")
+
+// codeReverted is the JSON-RPC error code of Conflux for a reverted call.
+const codeReverted = -32015
+
+// function selectors of the token views
+const (
+	selectorBalanceOf     = "70a08231" // balanceOf(address)
+	selectorOwnerOf       = "6352211e" // ownerOf(uint256)
+	selectorBalanceOf1155 = "00fdd58e" // balanceOf(address,uint256)
+)
+
+// BalanceOf returns the number of tokens of an ERC721 contract held by the owner.
+func BalanceOf(client *sdk.Client, contract, owner string) (*big.Int, error) {
+	ownerAddr, err := CommonAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	result, err := call(client, contract, selectorBalanceOf+encodeAddress(ownerAddr))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// BalanceOf1155 returns the amount of an ERC1155 token held by the owner.
+func BalanceOf1155(client *sdk.Client, contract, owner string, tokenId *big.Int) (*big.Int, error) {
+	ownerAddr, err := CommonAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	result, err := call(client, contract, selectorBalanceOf1155+encodeAddress(ownerAddr)+encodeWord(tokenId))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// OwnerOf returns the owner of an ERC721 token.
+func OwnerOf(client *sdk.Client, contract string, tokenId *big.Int) (common.Address, error) {
+	result, err := call(client, contract, selectorOwnerOf+encodeWord(tokenId))
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) < 32 {
+		return common.Address{}, fmt.Errorf("invalid ownerOf result %x", result)
+	}
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// CommonAddress returns the hex body of a base32 or hex address, which is how addresses appear in calls and events.
+func CommonAddress(addr string) (common.Address, error) {
+	if common.IsHexAddress(addr) {
+		return common.HexToAddress(addr), nil
+	}
+	cfxAddr, err := cfxaddress.NewFromBase32(addr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return cfxAddr.MustGetCommonAddress(), nil
+}
+
+func call(client *sdk.Client, contract, data string) ([]byte, error) {
+	to, err := cfxaddress.NewFromBase32(contract)
+	if err != nil {
+		return nil, err
+	}
+	data = "0x" + data
+	result, err := client.Call(types.CallRequest{To: &to, Data: &data}, nil)
+	if err != nil {
+		var rpcErr *sdkutils.RpcError
+		if errors.As(err, &rpcErr) && (rpcErr.Code == codeReverted || strings.Contains(strings.ToLower(rpcErr.Message), "revert")) {
+			return nil, fmt.Errorf("%w: %s", ErrReverted, rpcErr.Message)
+		}
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errors.New("empty call result, the contract may not exist")
+	}
+	return result, nil
+}
+
+// encodeWord and encodeAddress encode the arguments as 32 bytes ABI words.
+func encodeWord(val *big.Int) string {
+	return fmt.Sprintf("%064x", val)
+}
+
+func encodeAddress(addr common.Address) string {
+	return hex.EncodeToString(common.LeftPadBytes(addr.Bytes(), 32))
+}
@@ -0,0 +1,125 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	TransferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	TransferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+)
+
+// ErrMintMismatch means the transaction exists but did not mint the expected token to the claimant.
+var ErrMintMismatch = errors.New("mint does not match the transaction")
+
+// VerifyMint checks that the transaction succeeded and minted the token of the contract to the claimant, from the zero
+// address and in the amount of the record ("" for the single token of an ERC721), and that it is at least
+// confirmations epochs deep. It returns false without error while the transaction is not final yet.
+func VerifyMint(client *sdk.Client, hash, contract, to, tokenId, amount string, confirmations uint64) (bool, error) {
+	receipt, err := client.GetTransactionReceipt(types.Hash(hash))
+	if err != nil {
+		return false, err
+	}
+	if receipt == nil || receipt.EpochNumber == nil {
+		return false, nil
+	}
+	if receipt.OutcomeStatus != 0 {
+		return false, fmt.Errorf("%w: transaction %s failed", ErrMintMismatch, hash)
+	}
+
+	contractAddr, err := cfxaddress.NewFromBase32(contract)
+	if err != nil {
+		return false, err
+	}
+	toAddr, err := cfxaddress.NewFromBase32(to)
+	if err != nil {
+		return false, err
+	}
+	id, ok := new(big.Int).SetString(tokenId, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid token id %s", tokenId)
+	}
+	quantity := big.NewInt(1)
+	if amount != "" {
+		if quantity, ok = new(big.Int).SetString(amount, 10); !ok {
+			return false, fmt.Errorf("invalid amount %s", amount)
+		}
+	}
+
+	found := false
+	for _, log := range receipt.Logs {
+		if log.Address.GetHexAddress() != contractAddr.GetHexAddress() {
+			continue
+		}
+		transfer := ParseTransfer(log)
+		if transfer != nil && transfer.IsMint() && transfer.To == toAddr.MustGetCommonAddress() &&
+			transfer.TokenId.Cmp(id) == 0 && transfer.Amount.Cmp(quantity) == 0 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false, fmt.Errorf("%w: no mint of %s of token %s to %s in %s", ErrMintMismatch, quantity, tokenId, to, hash)
+	}
+
+	latest, err := client.GetEpochNumber(types.EpochLatestState)
+	if err != nil {
+		return false, err
+	}
+	return latest.ToInt().Uint64() >= uint64(*receipt.EpochNumber)+confirmations, nil
+}
+
+// Transfer is an ERC721 Transfer or an ERC1155 TransferSingle event.
+type Transfer struct {
+	From    common.Address
+	To      common.Address
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// IsMint reports whether the transfer comes from the zero address.
+func (t *Transfer) IsMint() bool {
+	return t.From == common.Address{}
+}
+
+// ParseTransfer decodes a transfer event, it returns nil for any other log.
+func ParseTransfer(log types.Log) *Transfer {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	switch common.HexToHash(string(log.Topics[0])) {
+	case TransferTopic:
+		if len(log.Topics) != 4 {
+			return nil
+		}
+		return &Transfer{
+			From:    topicAddress(log.Topics[1]),
+			To:      topicAddress(log.Topics[2]),
+			TokenId: common.HexToHash(string(log.Topics[3])).Big(),
+			Amount:  big.NewInt(1),
+		}
+	case TransferSingleTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil
+		}
+		return &Transfer{
+			From:    topicAddress(log.Topics[2]),
+			To:      topicAddress(log.Topics[3]),
+			TokenId: new(big.Int).SetBytes(log.Data[:32]),
+			Amount:  new(big.Int).SetBytes(log.Data[32:]),
+		}
+	}
+	return nil
+}
+
+func topicAddress(topic types.Hash) common.Address {
+	return common.BytesToAddress(common.HexToHash(string(topic)).Bytes()[12:])
+}
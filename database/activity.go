@@ -0,0 +1,130 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+)
+
+// Activity is what a member did in a guild: the messages counted and the scheduled events they are interested in.
+type Activity struct {
+	Messages         uint64
+	InterestedEvents uint64
+}
+
+// The activity bucket holds the counter "msg/<guild>/<user>" and a key "event/<guild>/<user>/<event>" for every
+// event the member is interested in, so an event counts once however often they toggle their interest.
+
+// CountMessage counts a message of the user in the guild.
+func CountMessage(guildID, userID string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ActivityBucket)
+		key := []byte("msg/" + guildID + "/" + userID)
+		return bucket.Put(key, encodeSupply(decodeSupply(bucket.Get(key))+1))
+	})
+}
+
+// SetEventInterest records whether the user is interested in the scheduled event of the guild.
+func SetEventInterest(guildID, userID, eventID string, interested bool) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ActivityBucket)
+		key := []byte("event/" + guildID + "/" + userID + "/" + eventID)
+		if interested {
+			return bucket.Put(key, []byte{1})
+		}
+		return bucket.Delete(key)
+	})
+}
+
+func GetActivity(guildID, userID string) (*Activity, error) {
+	activity := &Activity{}
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ActivityBucket)
+		activity.Messages = decodeSupply(bucket.Get([]byte("msg/" + guildID + "/" + userID)))
+		prefix := []byte("event/" + guildID + "/" + userID + "/")
+		c := bucket.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			activity.InterestedEvents++
+		}
+		return nil
+	})
+	return activity, err
+}
+
+// DynamicToken is a token whose metadata levels up with the activity of its holder. MetadataID is the metadata of
+// the token alone, BaseURI the metadata it was minted with, which every level is applied to.
+type DynamicToken struct {
+	Contract   string `json:"contract"`
+	TokenID    string `json:"token_id"`
+	Address    string `json:"address"`
+	UserID     string `json:"user_id"`
+	MetadataID string `json:"metadata_id"`
+	BaseURI    string `json:"base_uri"`
+	// Level is the level applied to the metadata, 0 until the first one is
+	Level  int    `json:"level"`
+	Points uint64 `json:"points"`
+}
+
+// PutDynamicToken adds or updates the token in the campaign, keyed by "<contract>/<token id>" in a nested bucket.
+func PutDynamicToken(campaign string, token *DynamicToken) error {
+	val, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(DynamicBucket).CreateBucketIfNotExists([]byte(campaign))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(token.Contract+"/"+token.TokenID), val)
+	})
+}
+
+// ResetDynamicLevel marks the token as not levelled, so the level job applies its level again. The reveal calls it
+// once it wrote the final metadata over a level the job may have applied to the placeholder.
+func ResetDynamicLevel(campaign, contract, tokenID string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(DynamicBucket).Bucket([]byte(campaign))
+		if bucket == nil {
+			return nil
+		}
+		key := []byte(contract + "/" + tokenID)
+		val := bucket.Get(key)
+		if val == nil {
+			return nil
+		}
+		var token DynamicToken
+		if err := json.Unmarshal(val, &token); err != nil {
+			return err
+		}
+		if token.Level == 0 {
+			return nil
+		}
+		token.Level = 0
+		val, err := json.Marshal(&token)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, val)
+	})
+}
+
+func GetDynamicTokens(campaign string) ([]*DynamicToken, error) {
+	var tokens []*DynamicToken
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(DynamicBucket).Bucket([]byte(campaign))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var token DynamicToken
+			if err := json.Unmarshal(v, &token); err != nil {
+				return err
+			}
+			tokens = append(tokens, &token)
+			return nil
+		})
+	})
+	return tokens, err
+}
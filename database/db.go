@@ -1,7 +1,13 @@
 package database
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"github.com/boltdb/bolt"
+	"github.com/nft-rainbow/discordBot/models"
 )
 
 
@@ -11,15 +17,35 @@ var (
 
 var EasyMintBucket = []byte("easy-mint-bucket")
 var CustomMintBucket = []byte("custom-mint-bucket")
+var UserAddressBucket = []byte("user-address-bucket")
+var MintRecordBucket = []byte("mint-record-bucket")
+var FeedCursorBucket = []byte("feed-cursor-bucket")
+var SupplyBucket = []byte("supply-bucket")
+var MetadataBucket = []byte("metadata-bucket")
+var RarityBucket = []byte("rarity-bucket")
+var CampaignSettingBucket = []byte("campaign-setting-bucket")
+var PoolBucket = []byte("pool-bucket")
+var RevealBucket = []byte("reveal-bucket")
+var ActivityBucket = []byte("activity-bucket")
+var DynamicBucket = []byte("dynamic-bucket")
+var WalletBucket = []byte("wallet-bucket")
 var EasyMintCache = make(map[string]bool)
 var CustomMintCache = make(map[string]bool)
 
 
 func ConnectDB(){
+	if err := OpenDB("./bolt.db"); err != nil {
+		panic(err)
+	}
+}
+
+// OpenDB opens the database at the path, e.g. the one of the bot for botCMD. Bolt locks the file, so it fails after a
+// second while another process, such as the running bot, has it open.
+func OpenDB(path string) error {
 	var err error
-	db, err = bolt.Open("./bolt.db", 0644, nil)
+	db, err = bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open %s: %w", path, err)
 	}
 
 	err = db.Update(func(tx *bolt.Tx) error{
@@ -31,11 +57,57 @@ func ConnectDB(){
 		if err != nil {
 			return err
 		}
+		_, err = tx.CreateBucketIfNotExists(UserAddressBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(MintRecordBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(FeedCursorBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(SupplyBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(MetadataBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(RarityBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(CampaignSettingBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(PoolBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(RevealBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(ActivityBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(DynamicBucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists(WalletBucket)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
-	if err != nil {
-		panic(err)
-	}
+	return err
 }
 
 func InsertDB(address string, val, bucketName []byte) error {
@@ -74,4 +146,336 @@ func GetStatus(address string, bucketName []byte) ([]byte, error) {
 		return nil, err
 	}
 	return val, nil
-}
\ No newline at end of file
+}
+
+// MigrateAddressKeys moves the entries of the bucket kept under an address as it was typed, before addresses were
+// normalized, to the address normalize returns. An entry already kept under that address is merged with the moved one.
+// Keys normalize rejects, such as those of another network, are left as they are. It returns how many were moved.
+func MigrateAddressKeys(bucketName []byte, normalize func(address string) (string, error)) (int, error) {
+	moved := 0
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		var keys []string
+		err := bucket.ForEach(func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			address, err := normalize(key)
+			if err != nil || address == key {
+				continue
+			}
+			val, err := mergeAddressEntries(bucketName, bucket.Get([]byte(address)), bucket.Get([]byte(key)))
+			if err != nil {
+				return fmt.Errorf("failed to merge the entries of %s and %s: %w", key, address, err)
+			}
+			if err = bucket.Put([]byte(address), val); err != nil {
+				return err
+			}
+			if err = bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+			moved++
+		}
+		return nil
+	})
+	return moved, err
+}
+
+// mergeAddressEntries merges the mint records of both, or keeps the status of a successful claim.
+func mergeAddressEntries(bucketName, current, legacy []byte) ([]byte, error) {
+	if current == nil {
+		return legacy, nil
+	}
+	if string(bucketName) != string(MintRecordBucket) {
+		if string(legacy) == "Success" {
+			return legacy, nil
+		}
+		return current, nil
+	}
+
+	var records, legacyRecords []*models.MintResp
+	if err := json.Unmarshal(current, &records); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(legacy, &legacyRecords); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		seen[record.Contract+"/"+record.TokenID] = true
+	}
+	for _, record := range legacyRecords {
+		if !seen[record.Contract+"/"+record.TokenID] {
+			records = append(records, record)
+		}
+	}
+	return json.Marshal(records)
+}
+
+// StartClaim marks the address Minting in the bucket unless it claimed already or is claiming, in a single
+// transaction so concurrent claims of the address cannot both start. It returns the status found, which is left as
+// it is when it is Success or Minting.
+func StartClaim(address string, bucketName []byte) ([]byte, error) {
+	var status []byte
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if val := bucket.Get([]byte(address)); val != nil {
+			// the value is only valid during the transaction
+			status = append([]byte{}, val...)
+		}
+		if string(status) == "Success" || string(status) == "Minting" {
+			return nil
+		}
+		return bucket.Put([]byte(address), []byte("Minting"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// LinkUser remembers the address a discord user claimed with, so that later commands can default to it.
+func LinkUser(userID, address string) error {
+	return InsertDB(userID, []byte(address), UserAddressBucket)
+}
+
+func GetUserAddress(userID string) (string, error) {
+	val, err := GetStatus(userID, UserAddressBucket)
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+// InsertMintRecord appends a successful mint to the records kept for the address.
+func InsertMintRecord(address string, record *models.MintResp) error {
+	key := []byte(address)
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(MintRecordBucket)
+		if err != nil {
+			return err
+		}
+
+		var records []*models.MintResp
+		if val := bucket.Get(key); val != nil {
+			if err = json.Unmarshal(val, &records); err != nil {
+				return err
+			}
+		}
+		records = append(records, record)
+
+		val, err := json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, val)
+	})
+}
+
+func GetMintRecords(address string) ([]*models.MintResp, error) {
+	val, err := GetStatus(address, MintRecordBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*models.MintResp
+	if val == nil {
+		return records, nil
+	}
+	if err = json.Unmarshal(val, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetAllMintRecords returns the mint records of every address.
+func GetAllMintRecords() (map[string][]*models.MintResp, error) {
+	result := make(map[string][]*models.MintResp)
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(MintRecordBucket).ForEach(func(k, v []byte) error {
+			var records []*models.MintResp
+			if err := json.Unmarshal(v, &records); err != nil {
+				return err
+			}
+			result[string(k)] = records
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateMintRecord replaces the record of the address which has the same contract and token id.
+func UpdateMintRecord(address string, record *models.MintResp) error {
+	key := []byte(address)
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(MintRecordBucket)
+
+		var records []*models.MintResp
+		if val := bucket.Get(key); val != nil {
+			if err := json.Unmarshal(val, &records); err != nil {
+				return err
+			}
+		}
+		for i, r := range records {
+			if r.Contract == record.Contract && r.TokenID == record.TokenID {
+				records[i] = record
+			}
+		}
+
+		val, err := json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, val)
+	})
+}
+
+// GetAllUserAddresses returns the linked address of every discord user.
+func GetAllUserAddresses() (map[string]string, error) {
+	result := make(map[string]string)
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(UserAddressBucket).ForEach(func(k, v []byte) error {
+			result[string(k)] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FeedCursor is where the transfer feed of a chain resumes: the logs from Epoch on, skipping the logs of Epoch which
+// have already been posted. Posted holds their "<transaction hash>/<log index in the transaction>", which stays the
+// same when the watched contracts change. Skip is the count of posted logs kept by cursors before Posted was.
+type FeedCursor struct {
+	Epoch  uint64   `json:"epoch"`
+	Posted []string `json:"posted,omitempty"`
+	Skip   int      `json:"skip,omitempty"`
+}
+
+// GetFeedCursor returns nil when the feed of the chain has never run.
+func GetFeedCursor(chain string) (*FeedCursor, error) {
+	val, err := GetStatus(chain, FeedCursorBucket)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	var cursor FeedCursor
+	if err = json.Unmarshal(val, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func SetFeedCursor(chain string, cursor *FeedCursor) error {
+	val, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return InsertDB(chain, val, FeedCursorBucket)
+}
+
+// GetMetadataURI returns the metadata uri created for the content hash, or "" when there is none yet.
+func GetMetadataURI(hash string) (string, error) {
+	val, err := GetStatus(hash, MetadataBucket)
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+func SetMetadataURI(hash, uri string) error {
+	return InsertDB(hash, []byte(uri), MetadataBucket)
+}
+
+// GetCampaignSetting returns a setting changed from Discord, such as "customMint.fileUrl", or "" when it was not.
+func GetCampaignSetting(key string) (string, error) {
+	val, err := GetStatus(key, CampaignSettingBucket)
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+func SetCampaignSetting(key, value string) error {
+	return InsertDB(key, []byte(value), CampaignSettingBucket)
+}
+
+// ReserveSupply counts units more against the supply of the key, unless that would exceed max. A max of 0 means no
+// limit. It returns the supply used including the reserved units, which is 0 when they could not be reserved.
+func ReserveSupply(key string, units, max uint64) (uint64, error) {
+	var reserved uint64
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(SupplyBucket)
+		used := decodeSupply(bucket.Get([]byte(key)))
+		if max > 0 && used+units > max {
+			return nil
+		}
+		reserved = used + units
+		return bucket.Put([]byte(key), encodeSupply(reserved))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reserved, nil
+}
+
+// ReleaseSupply gives back the units of a mint which failed.
+func ReleaseSupply(key string, units uint64) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(SupplyBucket)
+		used := decodeSupply(bucket.Get([]byte(key)))
+		if used < units {
+			used = units
+		}
+		return bucket.Put([]byte(key), encodeSupply(used-units))
+	})
+}
+
+// NextSerial numbers the next claim of the supply of the key. Unlike the supply the serials are never given back, so
+// a claim which failed leaves a gap rather than a number a later claim would repeat. Claims counted before the serials
+// were kept are numbered on from the supply already used, reserved units included.
+func NextSerial(key string, units uint64) (uint64, error) {
+	var serial uint64
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(SupplyBucket)
+		val := bucket.Get([]byte("serial/" + key))
+		if val == nil {
+			if used := decodeSupply(bucket.Get([]byte(key))) / units; used > 0 {
+				serial = used - 1
+			}
+		} else {
+			serial = decodeSupply(val)
+		}
+		serial++
+		return bucket.Put([]byte("serial/"+key), encodeSupply(serial))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return serial, nil
+}
+
+func encodeSupply(units uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, units)
+	return b
+}
+
+func decodeSupply(val []byte) uint64 {
+	if len(val) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(val)
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/utils"
+)
+
+// openTestDB opens a fresh database for the test and closes it afterwards.
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := OpenDB(filepath.Join(t.TempDir(), "bolt.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+}
+
+const (
+	testAddress        = "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4"
+	testVerboseAddress = "CFXTEST:TYPE.USER:AATP533CG7D0AGBD87KZ48NJ1MPNKCA8BE1RZ695J4"
+	testHexAddress     = "0x1ecde7223747601823f7535d7968ba98b4881e09"
+	testMainAddress    = "cfx:aatp533cg7d0agbd87kz48nj1mpnkca8be7ggp3vpu"
+)
+
+func normalizeTestAddress(address string) (string, error) {
+	return utils.NormalizeAddress(utils.CONFLUX_TEST, address)
+}
+
+func TestMigrateAddressKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		want    map[string]string
+		moved   int
+	}{
+		{
+			name:    "moves a legacy key",
+			entries: map[string]string{testVerboseAddress: "Success"},
+			want:    map[string]string{testAddress: "Success"},
+			moved:   1,
+		},
+		{
+			name:    "moves a hex key",
+			entries: map[string]string{testHexAddress: "Minting"},
+			want:    map[string]string{testAddress: "Minting"},
+			moved:   1,
+		},
+		{
+			name:    "keeps the success of the legacy key",
+			entries: map[string]string{testVerboseAddress: "Success", testAddress: "NoMinting"},
+			want:    map[string]string{testAddress: "Success"},
+			moved:   1,
+		},
+		{
+			name:    "keeps the success of the normalized key",
+			entries: map[string]string{testVerboseAddress: "NoMinting", testAddress: "Success"},
+			want:    map[string]string{testAddress: "Success"},
+			moved:   1,
+		},
+		{
+			name:    "leaves normalized and rejected keys alone",
+			entries: map[string]string{testAddress: "Success", testMainAddress: "Success"},
+			want:    map[string]string{testAddress: "Success", testMainAddress: "Success"},
+			moved:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			for key, status := range tt.entries {
+				if err := InsertDB(key, []byte(status), CustomMintBucket); err != nil {
+					t.Fatal(err)
+				}
+			}
+			moved, err := MigrateAddressKeys(CustomMintBucket, normalizeTestAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if moved != tt.moved {
+				t.Errorf("moved %d keys, want %d", moved, tt.moved)
+			}
+			for key := range tt.entries {
+				if _, ok := tt.want[key]; ok {
+					continue
+				}
+				if status, _ := GetStatus(key, CustomMintBucket); status != nil {
+					t.Errorf("legacy key %s is still there", key)
+				}
+			}
+			for key, want := range tt.want {
+				if status, _ := GetStatus(key, CustomMintBucket); string(status) != want {
+					t.Errorf("status of %s = %q, want %q", key, status, want)
+				}
+			}
+			if moved, err = MigrateAddressKeys(CustomMintBucket, normalizeTestAddress); err != nil || moved != 0 {
+				t.Errorf("second migration moved %d keys (%v), want none", moved, err)
+			}
+		})
+	}
+}
+
+func TestMigrateAddressKeysMergesMintRecords(t *testing.T) {
+	openTestDB(t)
+	for _, r := range []struct {
+		address string
+		tokenID string
+	}{
+		{testVerboseAddress, "1"},
+		{testVerboseAddress, "2"},
+		{testAddress, "2"},
+		{testAddress, "3"},
+	} {
+		if err := InsertMintRecord(r.address, &models.MintResp{Contract: "cfxtest:contract", TokenID: r.tokenID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := MigrateAddressKeys(MintRecordBucket, normalizeTestAddress); err != nil {
+		t.Fatal(err)
+	}
+	records, err := GetMintRecords(testAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	for _, record := range records {
+		tokens = append(tokens, record.TokenID)
+	}
+	if got := strings.Join(tokens, ","); got != "2,3,1" {
+		t.Errorf("merged tokens = %s, want 2,3,1", got)
+	}
+	if legacy, _ := GetMintRecords(testVerboseAddress); len(legacy) != 0 {
+		t.Errorf("legacy records are still there: %d", len(legacy))
+	}
+}
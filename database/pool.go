@@ -0,0 +1,276 @@
+package database
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/nft-rainbow/discordBot/models"
+)
+
+// PoolEntry is an item of the pool of a campaign, with the address it was handed out to and the token it was minted as.
+type PoolEntry struct {
+	Item     *models.CollectionItem `json:"item"`
+	TakenBy  string                 `json:"taken_by,omitempty"`
+	TakenAt  *time.Time             `json:"taken_at,omitempty"`
+	TokenID  string                 `json:"token_id,omitempty"`
+	MintedAt *time.Time             `json:"minted_at,omitempty"`
+}
+
+// poolRef points to the entry handed out to an address.
+type poolRef struct {
+	Manifest string `json:"manifest"`
+	Seq      uint64 `json:"seq"`
+}
+
+// The pool of a campaign is a nested bucket holding "taken/<address>", the poolRef of the entry handed out to the
+// address, and a nested bucket per manifest so collections with the same item ids do not clash. A manifest bucket
+// holds the entries under "item/<seq>" in the order they were added, "id/<id>" to find them by item id and the
+// counters "count" and "free". The free entries are kept twice: "free/<seq>" in order for sequential takes, and the
+// dense array "freeidx/<i>" with its inverse "freepos/<seq>" for uniform random takes. Both are updated in O(log n).
+
+// AddPoolItems adds the items of the manifest which are not in the pool of the campaign yet, and returns how many
+// were added.
+func AddPoolItems(campaign, manifest string, items []*models.CollectionItem) (int, error) {
+	added := 0
+	err := db.Update(func(tx *bolt.Tx) error {
+		pool, err := poolManifestBucket(tx, campaign, manifest)
+		if err != nil {
+			return err
+		}
+		count, free := decodeSupply(pool.Get([]byte("count"))), decodeSupply(pool.Get([]byte("free")))
+		for _, item := range items {
+			if pool.Get([]byte("id/"+item.ID)) != nil {
+				continue
+			}
+			val, err := json.Marshal(&PoolEntry{Item: item})
+			if err != nil {
+				return err
+			}
+			seq := count
+			if err = pool.Put(poolKey("item/", seq), val); err != nil {
+				return err
+			}
+			if err = pool.Put([]byte("id/"+item.ID), encodeSupply(seq)); err != nil {
+				return err
+			}
+			if err = pushFree(pool, seq, free); err != nil {
+				return err
+			}
+			count++
+			free++
+			added++
+		}
+		if err = pool.Put([]byte("count"), encodeSupply(count)); err != nil {
+			return err
+		}
+		return pool.Put([]byte("free"), encodeSupply(free))
+	})
+	return added, err
+}
+
+// TakePoolItem hands an item of the manifest out to the address, the first free one or a random one. An address which
+// was handed an item before gets the same one again, so a claim retried after a failure or a crash never takes a
+// second item. It returns a nil item when the pool is empty, along with the number of free items of the manifest.
+func TakePoolItem(campaign, manifest, address string, random bool) (*models.CollectionItem, uint64, error) {
+	var item *models.CollectionItem
+	var free uint64
+	err := db.Update(func(tx *bolt.Tx) error {
+		pool, err := poolManifestBucket(tx, campaign, manifest)
+		if err != nil {
+			return err
+		}
+		free = decodeSupply(pool.Get([]byte("free")))
+		if entry, _, _, err := takenPoolEntry(tx, campaign, address); err != nil || entry != nil {
+			if entry != nil {
+				item = entry.Item
+			}
+			return err
+		}
+		if free == 0 {
+			return nil
+		}
+
+		var seq uint64
+		if random {
+			r, err := rand.Int(rand.Reader, new(big.Int).SetUint64(free))
+			if err != nil {
+				return err
+			}
+			seq = decodeSupply(pool.Get(poolKey("freeidx/", r.Uint64())))
+		} else {
+			k, _ := pool.Cursor().Seek([]byte("free/"))
+			if k == nil || !bytes.HasPrefix(k, []byte("free/")) {
+				return fmt.Errorf("the pool of %s counts %d free items but none was found", campaign, free)
+			}
+			seq = binary.BigEndian.Uint64(k[5:])
+		}
+		if err = popFree(pool, seq, free); err != nil {
+			return err
+		}
+		free--
+
+		var entry PoolEntry
+		if err = json.Unmarshal(pool.Get(poolKey("item/", seq)), &entry); err != nil {
+			return err
+		}
+		now := time.Now()
+		entry.TakenBy = address
+		entry.TakenAt = &now
+		if err = putPoolEntry(pool, seq, &entry); err != nil {
+			return err
+		}
+		ref, err := json.Marshal(&poolRef{Manifest: manifest, Seq: seq})
+		if err != nil {
+			return err
+		}
+		if err = tx.Bucket(PoolBucket).Bucket([]byte(campaign)).Put([]byte("taken/"+address), ref); err != nil {
+			return err
+		}
+		item = entry.Item
+		return pool.Put([]byte("free"), encodeSupply(free))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return item, free, nil
+}
+
+// MarkPoolItemMinted records the token the item handed out to the address was minted as. A minted item is never
+// released. It returns the number of free items left in the manifest of the item.
+func MarkPoolItemMinted(campaign, address, tokenID string) (uint64, error) {
+	var free uint64
+	err := db.Update(func(tx *bolt.Tx) error {
+		entry, pool, seq, err := takenPoolEntry(tx, campaign, address)
+		if err != nil || entry == nil {
+			return err
+		}
+		now := time.Now()
+		entry.TokenID = tokenID
+		entry.MintedAt = &now
+		free = decodeSupply(pool.Get([]byte("free")))
+		return putPoolEntry(pool, seq, entry)
+	})
+	return free, err
+}
+
+// PoolItemHeld tells whether an item was handed out to the address but is not minted yet, so its claim can still be
+// retried once the pool is empty.
+func PoolItemHeld(campaign, address string) (bool, error) {
+	held := false
+	err := db.View(func(tx *bolt.Tx) error {
+		entry, _, _, err := takenPoolEntry(tx, campaign, address)
+		held = entry != nil && entry.MintedAt == nil
+		return err
+	})
+	return held, err
+}
+
+// ReleasePoolItem puts the item handed out to the address back into the pool, unless it was minted. It tells whether
+// an item was released.
+func ReleasePoolItem(campaign, address string) (bool, error) {
+	released := false
+	err := db.Update(func(tx *bolt.Tx) error {
+		entry, pool, seq, err := takenPoolEntry(tx, campaign, address)
+		if err != nil || entry == nil || entry.MintedAt != nil {
+			return err
+		}
+		entry.TakenBy, entry.TakenAt = "", nil
+		if err = putPoolEntry(pool, seq, entry); err != nil {
+			return err
+		}
+		if err = tx.Bucket(PoolBucket).Bucket([]byte(campaign)).Delete([]byte("taken/" + address)); err != nil {
+			return err
+		}
+		free := decodeSupply(pool.Get([]byte("free")))
+		if err = pushFree(pool, seq, free); err != nil {
+			return err
+		}
+		released = true
+		return pool.Put([]byte("free"), encodeSupply(free+1))
+	})
+	return released, err
+}
+
+// takenPoolEntry returns the entry handed out to the address with its manifest bucket and sequence, or a nil entry.
+func takenPoolEntry(tx *bolt.Tx, campaign, address string) (*PoolEntry, *bolt.Bucket, uint64, error) {
+	bucket := tx.Bucket(PoolBucket).Bucket([]byte(campaign))
+	if bucket == nil {
+		return nil, nil, 0, nil
+	}
+	val := bucket.Get([]byte("taken/" + address))
+	if val == nil {
+		return nil, nil, 0, nil
+	}
+	var ref poolRef
+	if err := json.Unmarshal(val, &ref); err != nil {
+		return nil, nil, 0, err
+	}
+	pool := bucket.Bucket([]byte("m/" + ref.Manifest))
+	if pool == nil {
+		return nil, nil, 0, fmt.Errorf("the pool of %s has no manifest %s", campaign, ref.Manifest)
+	}
+	var entry PoolEntry
+	if err := json.Unmarshal(pool.Get(poolKey("item/", ref.Seq)), &entry); err != nil {
+		return nil, nil, 0, err
+	}
+	return &entry, pool, ref.Seq, nil
+}
+
+func poolManifestBucket(tx *bolt.Tx, campaign, manifest string) (*bolt.Bucket, error) {
+	bucket, err := tx.Bucket(PoolBucket).CreateBucketIfNotExists([]byte(campaign))
+	if err != nil {
+		return nil, err
+	}
+	return bucket.CreateBucketIfNotExists([]byte("m/" + manifest))
+}
+
+func putPoolEntry(pool *bolt.Bucket, seq uint64, entry *PoolEntry) error {
+	val, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return pool.Put(poolKey("item/", seq), val)
+}
+
+// pushFree adds the entry to both free sets, free being the number of free entries before.
+func pushFree(pool *bolt.Bucket, seq, free uint64) error {
+	if err := pool.Put(poolKey("free/", seq), []byte{}); err != nil {
+		return err
+	}
+	if err := pool.Put(poolKey("freeidx/", free), encodeSupply(seq)); err != nil {
+		return err
+	}
+	return pool.Put(poolKey("freepos/", seq), encodeSupply(free))
+}
+
+// popFree removes the entry from both free sets, moving the last one of the dense array to its place.
+func popFree(pool *bolt.Bucket, seq, free uint64) error {
+	pos := decodeSupply(pool.Get(poolKey("freepos/", seq)))
+	last := decodeSupply(pool.Get(poolKey("freeidx/", free-1)))
+	if err := pool.Put(poolKey("freeidx/", pos), encodeSupply(last)); err != nil {
+		return err
+	}
+	if err := pool.Put(poolKey("freepos/", last), encodeSupply(pos)); err != nil {
+		return err
+	}
+	if err := pool.Delete(poolKey("freeidx/", free-1)); err != nil {
+		return err
+	}
+	if err := pool.Delete(poolKey("freepos/", seq)); err != nil {
+		return err
+	}
+	return pool.Delete(poolKey("free/", seq))
+}
+
+func poolKey(prefix string, seq uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], seq)
+	return key
+}
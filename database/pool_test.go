@@ -0,0 +1,218 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/nft-rainbow/discordBot/models"
+)
+
+func testPoolItems(n int) []*models.CollectionItem {
+	items := make([]*models.CollectionItem, n)
+	for i := range items {
+		items[i] = &models.CollectionItem{ID: fmt.Sprint(i), Name: fmt.Sprintf("Item %d", i)}
+	}
+	return items
+}
+
+func addTestPool(t *testing.T, n int) {
+	t.Helper()
+	added, err := AddPoolItems("customMint", "manifest", testPoolItems(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != n {
+		t.Fatalf("AddPoolItems added %d items, want %d", added, n)
+	}
+}
+
+// checkFreeSets fails unless both free sets of the manifest hold exactly the free entries.
+func checkFreeSets(t *testing.T, manifest string, wantFree int) {
+	t.Helper()
+	err := db.View(func(tx *bolt.Tx) error {
+		pool := tx.Bucket(PoolBucket).Bucket([]byte("customMint")).Bucket([]byte("m/" + manifest))
+		free := decodeSupply(pool.Get([]byte("free")))
+		if free != uint64(wantFree) {
+			return fmt.Errorf("free = %d, want %d", free, wantFree)
+		}
+		ordered := map[uint64]bool{}
+		c := pool.Cursor()
+		for k, _ := c.Seek([]byte("free/")); k != nil && bytes.HasPrefix(k, []byte("free/")); k, _ = c.Next() {
+			ordered[decodeSupply(k[5:])] = true
+		}
+		if uint64(len(ordered)) != free {
+			return fmt.Errorf("the ordered free set holds %d entries, want %d", len(ordered), free)
+		}
+		for i := uint64(0); i < free; i++ {
+			seq := decodeSupply(pool.Get(poolKey("freeidx/", i)))
+			if !ordered[seq] {
+				return fmt.Errorf("freeidx/%d holds %d which is not free", i, seq)
+			}
+			if pos := decodeSupply(pool.Get(poolKey("freepos/", seq))); pos != i {
+				return fmt.Errorf("freepos/%d = %d, want %d", seq, pos, i)
+			}
+		}
+		if pool.Get(poolKey("freeidx/", free)) != nil {
+			return fmt.Errorf("freeidx/%d is set past the free entries", free)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddPoolItemsSkipsKnownItems(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 3)
+	added, err := AddPoolItems("customMint", "manifest", testPoolItems(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Errorf("AddPoolItems added %d items, want 2", added)
+	}
+	checkFreeSets(t, "manifest", 5)
+}
+
+func TestTakePoolItemInOrder(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 3)
+	for i := 0; i < 3; i++ {
+		item, free, err := TakePoolItem("customMint", "manifest", fmt.Sprintf("address%d", i), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item == nil || item.ID != fmt.Sprint(i) {
+			t.Fatalf("take %d got item %+v, want %d", i, item, i)
+		}
+		if free != uint64(2-i) {
+			t.Errorf("take %d left %d free items, want %d", i, free, 2-i)
+		}
+		checkFreeSets(t, "manifest", 2-i)
+	}
+	item, free, err := TakePoolItem("customMint", "manifest", "address3", false)
+	if err != nil || item != nil || free != 0 {
+		t.Errorf("take from the empty pool = %+v, %d, %v, want no item", item, free, err)
+	}
+}
+
+func TestTakePoolItemRandom(t *testing.T) {
+	openTestDB(t)
+	const n = 50
+	addTestPool(t, n)
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		item, _, err := TakePoolItem("customMint", "manifest", fmt.Sprintf("address%d", i), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item == nil {
+			t.Fatalf("take %d got no item", i)
+		}
+		if seen[item.ID] {
+			t.Fatalf("item %s was handed out twice", item.ID)
+		}
+		seen[item.ID] = true
+		checkFreeSets(t, "manifest", n-1-i)
+	}
+	if item, _, err := TakePoolItem("customMint", "manifest", "late", true); err != nil || item != nil {
+		t.Errorf("take from the empty pool = %+v, %v, want no item", item, err)
+	}
+}
+
+func TestTakePoolItemSameAddress(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 10)
+	first, _, err := TakePoolItem("customMint", "manifest", testAddress, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, free, err := TakePoolItem("customMint", "manifest", testAddress, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == nil || again.ID != first.ID {
+		t.Errorf("the retried take got %+v, want item %s", again, first.ID)
+	}
+	if free != 9 {
+		t.Errorf("the retried take left %d free items, want 9", free)
+	}
+	checkFreeSets(t, "manifest", 9)
+}
+
+func TestReleasePoolItem(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 3)
+	for _, address := range []string{"a", "b", "c"} {
+		if _, _, err := TakePoolItem("customMint", "manifest", address, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	released, err := ReleasePoolItem("customMint", "b")
+	if err != nil || !released {
+		t.Fatalf("ReleasePoolItem = %v, %v, want released", released, err)
+	}
+	checkFreeSets(t, "manifest", 1)
+	if held, _ := PoolItemHeld("customMint", "b"); held {
+		t.Error("the released item is still held")
+	}
+	item, _, err := TakePoolItem("customMint", "manifest", "d", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item == nil || item.ID != "1" {
+		t.Errorf("the take after the release got %+v, want item 1", item)
+	}
+
+	if released, err = ReleasePoolItem("customMint", "nobody"); err != nil || released {
+		t.Errorf("ReleasePoolItem of an address without item = %v, %v", released, err)
+	}
+}
+
+func TestReleasePoolItemKeepsMinted(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 2)
+	if _, _, err := TakePoolItem("customMint", "manifest", "a", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MarkPoolItemMinted("customMint", "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	released, err := ReleasePoolItem("customMint", "a")
+	if err != nil || released {
+		t.Errorf("ReleasePoolItem of a minted item = %v, %v, want kept", released, err)
+	}
+	checkFreeSets(t, "manifest", 1)
+}
+
+// The campaign closes once MarkPoolItemMinted reports no free items.
+func TestMarkPoolItemMintedEmptiesPool(t *testing.T) {
+	openTestDB(t)
+	addTestPool(t, 2)
+	for i, address := range []string{"a", "b"} {
+		if _, _, err := TakePoolItem("customMint", "manifest", address, true); err != nil {
+			t.Fatal(err)
+		}
+		if held, _ := PoolItemHeld("customMint", address); !held {
+			t.Errorf("the item of %s is not held before its mint", address)
+		}
+		free, err := MarkPoolItemMinted("customMint", address, fmt.Sprint(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if free != uint64(1-i) {
+			t.Errorf("MarkPoolItemMinted left %d free items, want %d", free, 1-i)
+		}
+		if held, _ := PoolItemHeld("customMint", address); held {
+			t.Errorf("the item of %s is held after its mint", address)
+		}
+	}
+
+	if item, free, err := TakePoolItem("customMint", "manifest", "c", true); err != nil || item != nil || free != 0 {
+		t.Errorf("take from the exhausted pool = %+v, %d, %v, want no item", item, free, err)
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+	"github.com/nft-rainbow/discordBot/models"
+)
+
+// RarityDraw is the audit record of the traits drawn for an address. Seed is sha256 of the campaign seed and the
+// address, so the draw can be replayed from the stored campaign seed.
+type RarityDraw struct {
+	Seed   string          `json:"seed"`
+	Traits []*models.Trait `json:"traits"`
+}
+
+// DrawRarity returns the traits drawn for the address in the campaign, drawing them the first time. The draw function
+// gets the seed of the address and the number of times each trait value has been drawn; it runs in the same
+// transaction which counts the drawn values, so caps hold under concurrent claims.
+func DrawRarity(campaign, address string, draw func(seed []byte, count func(traitType, value string) uint64) ([]*models.Trait, error)) (*RarityDraw, error) {
+	var result *RarityDraw
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(RarityBucket)
+		drawKey := []byte("draw/" + campaign + "/" + address)
+		if val := bucket.Get(drawKey); val != nil {
+			result = &RarityDraw{}
+			return json.Unmarshal(val, result)
+		}
+
+		campaignSeed, err := rarityCampaignSeed(bucket, campaign)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(append(campaignSeed, []byte(address)...))
+		countKey := func(traitType, value string) []byte {
+			return []byte("count/" + campaign + "/" + traitType + "/" + value)
+		}
+		traits, err := draw(sum[:], func(traitType, value string) uint64 {
+			return decodeSupply(bucket.Get(countKey(traitType, value)))
+		})
+		if err != nil {
+			return err
+		}
+		for _, trait := range traits {
+			key := countKey(trait.TraitType, trait.Value)
+			if err = bucket.Put(key, encodeSupply(decodeSupply(bucket.Get(key))+1)); err != nil {
+				return err
+			}
+		}
+
+		result = &RarityDraw{Seed: hex.EncodeToString(sum[:]), Traits: traits}
+		val, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(drawKey, val)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReleaseRarity gives back the traits drawn for the address when its claim failed before the mint was requested, so
+// they count against the caps no more and the address draws again on its next claim.
+func ReleaseRarity(campaign, address string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(RarityBucket)
+		drawKey := []byte("draw/" + campaign + "/" + address)
+		val := bucket.Get(drawKey)
+		if val == nil {
+			return nil
+		}
+		var record RarityDraw
+		if err := json.Unmarshal(val, &record); err != nil {
+			return err
+		}
+		for _, trait := range record.Traits {
+			key := []byte("count/" + campaign + "/" + trait.TraitType + "/" + trait.Value)
+			if count := decodeSupply(bucket.Get(key)); count > 0 {
+				if err := bucket.Put(key, encodeSupply(count-1)); err != nil {
+					return err
+				}
+			}
+		}
+		return bucket.Delete(drawKey)
+	})
+}
+
+func rarityCampaignSeed(bucket *bolt.Bucket, campaign string) ([]byte, error) {
+	key := []byte("seed/" + campaign)
+	if val := bucket.Get(key); val != nil {
+		// the value is only valid during the transaction
+		return append([]byte{}, val...), nil
+	}
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, bucket.Put(key, seed)
+}
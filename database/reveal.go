@@ -0,0 +1,104 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// RevealEntry maps a token minted with placeholder metadata to its final metadata. The placeholder is created for the
+// token alone, so revealing it updates the metadata behind its uri and the token uri on chain stays the same. The
+// entry is recorded before the mint is requested, its TokenID and MintedAt are filled in once the token is minted.
+type RevealEntry struct {
+	Contract       string     `json:"contract"`
+	TokenID        string     `json:"token_id"`
+	Address        string     `json:"address"`
+	UserID         string     `json:"user_id,omitempty"`
+	PlaceholderID  string     `json:"placeholder_id"`
+	PlaceholderURI string     `json:"placeholder_uri"`
+	FinalURI       string     `json:"final_uri"`
+	RequestedAt    time.Time  `json:"requested_at"`
+	MintedAt       time.Time  `json:"minted_at"`
+	RevealedAt     *time.Time `json:"revealed_at,omitempty"`
+	// Error is why the last reveal of the token failed
+	Error string `json:"error,omitempty"`
+}
+
+// The entries of a campaign are kept in a nested bucket, keyed by the id of their placeholder, which is known before
+// the token is minted.
+
+func revealKey(entry *RevealEntry) []byte {
+	return []byte(entry.PlaceholderID)
+}
+
+// PutRevealEntry adds or updates the entry of the token in the campaign.
+func PutRevealEntry(campaign string, entry *RevealEntry) error {
+	val, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(RevealBucket).CreateBucketIfNotExists([]byte(campaign))
+		if err != nil {
+			return err
+		}
+		return bucket.Put(revealKey(entry), val)
+	})
+}
+
+// GetRevealEntries returns the entries of the campaign, revealed or not.
+func GetRevealEntries(campaign string) ([]*RevealEntry, error) {
+	var entries []*RevealEntry
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(RevealBucket).Bucket([]byte(campaign))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var entry RevealEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, &entry)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// SetRevealToken records the token the entry of the placeholder was minted as, leaving the rest of the entry as it is.
+func SetRevealToken(campaign, placeholderID, contract, tokenID string, mintedAt time.Time) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(RevealBucket).Bucket([]byte(campaign))
+		if bucket == nil {
+			return fmt.Errorf("%s has no placeholder %s", campaign, placeholderID)
+		}
+		val := bucket.Get([]byte(placeholderID))
+		if val == nil {
+			return fmt.Errorf("%s has no placeholder %s", campaign, placeholderID)
+		}
+		var entry RevealEntry
+		if err := json.Unmarshal(val, &entry); err != nil {
+			return err
+		}
+		entry.Contract, entry.TokenID, entry.MintedAt = contract, tokenID, mintedAt
+		val, err := json.Marshal(&entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(placeholderID), val)
+	})
+}
+
+// DeleteRevealEntry forgets the entry of a placeholder which was never minted.
+func DeleteRevealEntry(campaign, placeholderID string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(RevealBucket).Bucket([]byte(campaign))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(placeholderID))
+	})
+}
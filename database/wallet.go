@@ -0,0 +1,65 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// PendingWallet is the message a user has to sign to prove they own the address.
+type PendingWallet struct {
+	Address   string    `json:"address"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// The wallet bucket keeps "pending/<user>" until the user proves the address, then "verified/<user>". Verified
+// wallets are separate from the addresses users claim with, which nothing proves they own.
+
+func SetPendingWallet(userID string, pending *PendingWallet) error {
+	val, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return InsertDB("pending/"+userID, val, WalletBucket)
+}
+
+// GetPendingWallet returns nil when the user has no wallet waiting for its proof.
+func GetPendingWallet(userID string) (*PendingWallet, error) {
+	val, err := GetStatus("pending/"+userID, WalletBucket)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	var pending PendingWallet
+	if err = json.Unmarshal(val, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+// VerifyWallet makes the pending address the verified wallet of the user.
+func VerifyWallet(userID, address string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(WalletBucket)
+		if err := bucket.Delete([]byte("pending/" + userID)); err != nil {
+			return err
+		}
+		return bucket.Put([]byte("verified/"+userID), []byte(address))
+	})
+}
+
+// GetVerifiedWallets returns the verified wallet of every user who has one.
+func GetVerifiedWallets() (map[string]string, error) {
+	result := make(map[string]string)
+	err := db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(WalletBucket).Cursor()
+		prefix := []byte("verified/")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			result[string(k[len(prefix):])] = string(v)
+		}
+		return nil
+	})
+	return result, err
+}
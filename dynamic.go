@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/viper"
+)
+
+// dynamicLevel is reached once the holder has the points. Image replaces the image of the token from this level on.
+type dynamicLevel struct {
+	Level  int    `mapstructure:"level"`
+	Points uint64 `mapstructure:"points"`
+	Name   string `mapstructure:"name"`
+	Image  string `mapstructure:"image"`
+}
+
+// messageCounted is when a message of each member was last counted, messages within dynamic.messageCooldown of it
+// are not.
+var (
+	messageCountedMu sync.Mutex
+	messageCounted   = make(map[string]time.Time)
+)
+
+// dynamicMu keeps the level job from writing the metadata of the tokens while a reveal does.
+var dynamicMu sync.Mutex
+
+// dynamicEnabled tells whether the tokens of the campaign level up with the activity of their holders.
+func dynamicEnabled(campaign string) bool {
+	return viper.GetBool("dynamic.enabled") && dynamicCampaign() == campaign
+}
+
+func dynamicCampaign() string {
+	if campaign := viper.GetString("dynamic.campaign"); campaign != "" {
+		return campaign
+	}
+	return "customMint"
+}
+
+// dynamicLevels returns the levels of the config by the points they need, which must rise with the points.
+func dynamicLevels() ([]dynamicLevel, error) {
+	var levels []dynamicLevel
+	if err := viper.UnmarshalKey("dynamic.levels", &levels); err != nil {
+		return nil, fmt.Errorf("invalid dynamic.levels: %w", err)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Points < levels[j].Points
+	})
+	// a token at level 0 is not levelled yet, and a level needing more points must be a higher one
+	for i, level := range levels {
+		if level.Level <= 0 {
+			return nil, fmt.Errorf("invalid dynamic.levels: level %d must be above 0", level.Level)
+		}
+		if i > 0 && level.Level <= levels[i-1].Level {
+			return nil, fmt.Errorf("invalid dynamic.levels: level %d needs more points than level %d", levels[i-1].Level, level.Level)
+		}
+	}
+	return levels, nil
+}
+
+// campaignDynamicToken gives the token metadata of its own, a copy of the metadata it would be minted with, which
+// the level job updates. A token minted with a placeholder has its own metadata already, it is levelled once revealed.
+func campaignDynamicToken(token, metadataUri string, revealEntry *database.RevealEntry) (*database.DynamicToken, string, error) {
+	if revealEntry != nil {
+		return &database.DynamicToken{MetadataID: revealEntry.PlaceholderID, BaseURI: revealEntry.FinalURI}, metadataUri, nil
+	}
+	metadata, err := service.GetMetadata(metadataUri)
+	if err != nil {
+		return nil, "", newClaimError(errUpstream, fmt.Errorf("failed to read the metadata %s: %w", metadataUri, err))
+	}
+	metadata.ID, metadata.URI = "", ""
+	res, err := service.CreateMetadataInfo(token, *metadata)
+	if err != nil {
+		return nil, "", newClaimError(errUpstream, err)
+	}
+	if res.MetadataID == "" || res.MetadataURI == "" {
+		return nil, "", newClaimError(errUpstream, fmt.Errorf("NFTRainbow returned no id or uri for the metadata of a dynamic token"))
+	}
+	return &database.DynamicToken{MetadataID: res.MetadataID, BaseURI: metadataUri}, res.MetadataURI, nil
+}
+
+// recordDynamicToken keeps the minted token for the level job. The token is minted already, so a failure only alerts
+// the admins.
+func recordDynamicToken(campaign string, token *database.DynamicToken) {
+	if err := database.PutDynamicToken(campaign, token); err != nil {
+		log.Printf("Failed to record the dynamic token %s: %v", token.TokenID, err)
+		sendAdminLog(s, &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       "Dynamic token not recorded",
+			Description: err.Error(),
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Campaign", Value: campaign, Inline: true},
+				{Name: "Token ID", Value: token.TokenID, Inline: true},
+				{Name: "Metadata", Value: token.MetadataID},
+			},
+		})
+	}
+}
+
+// runDynamicNFTs counts the activity of the members of dynamic.guildId and periodically levels up the tokens of
+// their claims.
+func runDynamicNFTs(s *discordgo.Session) {
+	if !viper.GetBool("dynamic.enabled") {
+		return
+	}
+	trackActivity(s)
+	interval := viper.GetDuration("dynamic.interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	for {
+		updateDynamicNFTs(s)
+		time.Sleep(interval)
+	}
+}
+
+func trackActivity(s *discordgo.Session) {
+	guildID := viper.GetString("dynamic.guildId")
+	s.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.GuildID != guildID || m.Author == nil || m.Author.Bot {
+			return
+		}
+		cooldown := viper.GetDuration("dynamic.messageCooldown")
+		messageCountedMu.Lock()
+		last, ok := messageCounted[m.Author.ID]
+		counted := !ok || time.Since(last) >= cooldown
+		if counted {
+			messageCounted[m.Author.ID] = time.Now()
+		}
+		messageCountedMu.Unlock()
+		if !counted {
+			return
+		}
+		if err := database.CountMessage(guildID, m.Author.ID); err != nil {
+			log.Printf("Failed to count a message of %s: %v", m.Author.ID, err)
+		}
+	})
+	s.AddHandler(func(s *discordgo.Session, e *discordgo.GuildScheduledEventUserAdd) {
+		if e.GuildID != guildID {
+			return
+		}
+		if err := database.SetEventInterest(guildID, e.UserID, e.GuildScheduledEventID, true); err != nil {
+			log.Printf("Failed to count event %s for %s: %v", e.GuildScheduledEventID, e.UserID, err)
+		}
+	})
+	s.AddHandler(func(s *discordgo.Session, e *discordgo.GuildScheduledEventUserRemove) {
+		if e.GuildID != guildID {
+			return
+		}
+		if err := database.SetEventInterest(guildID, e.UserID, e.GuildScheduledEventID, false); err != nil {
+			log.Printf("Failed to uncount event %s for %s: %v", e.GuildScheduledEventID, e.UserID, err)
+		}
+	})
+}
+
+// updateDynamicNFTs levels up the tokens whose holder, the member who claimed them, reached a higher level. Tokens
+// are left alone until their campaign is revealed, the levels would give the placeholders away.
+func updateDynamicNFTs(s *discordgo.Session) {
+	dynamicMu.Lock()
+	defer dynamicMu.Unlock()
+	campaign := dynamicCampaign()
+	if revealPending(campaign) {
+		return
+	}
+	levels, err := dynamicLevels()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if len(levels) == 0 {
+		return
+	}
+	tokens, err := database.GetDynamicTokens(campaign)
+	if err != nil {
+		log.Printf("Failed to load the dynamic tokens: %v", err)
+		return
+	}
+
+	guildID := viper.GetString("dynamic.guildId")
+	points := make(map[string]uint64)
+	apiToken := ""
+	for _, token := range tokens {
+		if token.UserID == "" {
+			continue
+		}
+		p, ok := points[token.UserID]
+		if !ok {
+			if p, err = memberPoints(s, guildID, token.UserID); err != nil {
+				// the member left the guild, the token keeps its level
+				continue
+			}
+			points[token.UserID] = p
+		}
+		level := 0
+		for i := range levels {
+			if levels[i].Points <= p {
+				level = i + 1
+			}
+		}
+		if level == 0 || levels[level-1].Level <= token.Level {
+			continue
+		}
+
+		if apiToken == "" {
+			if apiToken, err = service.Login(); err != nil {
+				log.Printf("Failed to login to level up the dynamic tokens: %v", err)
+				return
+			}
+		}
+		metadata, err := levelMetadata(token, levels[:level])
+		if err == nil {
+			err = service.UpdateMetadata(apiToken, token.MetadataID, *metadata)
+		}
+		if err != nil {
+			log.Printf("Failed to level up token %s of %s: %v", token.TokenID, token.Contract, err)
+			continue
+		}
+
+		previous := token.Level
+		token.Level, token.Points = levels[level-1].Level, p
+		if err = database.PutDynamicToken(campaign, token); err != nil {
+			log.Printf("Failed to record the level of token %s: %v", token.TokenID, err)
+		}
+		// the first level is applied silently after the claim or the reveal
+		if previous > 0 {
+			announceLevelUp(s, token, &levels[level-1], metadata)
+		}
+	}
+}
+
+// memberPoints weighs the messages, the events the member is interested in and the days in the guild of the member with dynamic.points.
+func memberPoints(s *discordgo.Session, guildID, userID string) (uint64, error) {
+	member, err := s.State.Member(guildID, userID)
+	if err != nil {
+		if member, err = s.GuildMember(guildID, userID); err != nil {
+			return 0, err
+		}
+	}
+	activity, err := database.GetActivity(guildID, userID)
+	if err != nil {
+		return 0, err
+	}
+	days := uint64(0)
+	if !member.JoinedAt.IsZero() && time.Since(member.JoinedAt) > 0 {
+		days = uint64(time.Since(member.JoinedAt) / (24 * time.Hour))
+	}
+	return activity.Messages*viper.GetUint64("dynamic.points.message") +
+		activity.InterestedEvents*viper.GetUint64("dynamic.points.interestedEvent") +
+		days*viper.GetUint64("dynamic.points.day"), nil
+}
+
+// levelMetadata applies the last of the reached levels to the metadata the token was minted with: the Level and Rank
+// attributes, and the image of the highest level which has one.
+func levelMetadata(token *database.DynamicToken, reached []dynamicLevel) (*models.Metadata, error) {
+	metadata, err := service.GetMetadata(token.BaseURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the metadata %s: %w", token.BaseURI, err)
+	}
+	metadata.ID, metadata.URI = "", ""
+	level := reached[len(reached)-1]
+	metadata.Attributes = append(metadata.Attributes, models.Attributes{
+		TraitType:   "Level",
+		DisplayType: "number",
+		Value:       strconv.Itoa(level.Level),
+	})
+	if level.Name != "" {
+		metadata.Attributes = append(metadata.Attributes, models.Attributes{TraitType: "Rank", Value: level.Name})
+	}
+	for _, l := range reached {
+		if l.Image != "" {
+			metadata.Image = l.Image
+		}
+	}
+	return metadata, nil
+}
+
+func announceLevelUp(s *discordgo.Session, token *database.DynamicToken, level *dynamicLevel, metadata *models.Metadata) {
+	channelID := viper.GetString("dynamic.channelId")
+	if channelID == "" {
+		return
+	}
+	locale := i18n.Pick()
+	embed := &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: metadata.Name,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: i18n.T(locale, "embed.field.token_id"), Value: token.TokenID, Inline: true},
+			{Name: i18n.T(locale, "embed.field.level"), Value: strconv.Itoa(level.Level), Inline: true},
+		},
+	}
+	if level.Name != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: i18n.T(locale, "embed.field.rank"), Value: level.Name, Inline: true})
+	}
+	if metadata.Image != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: metadata.Image}
+	}
+	_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: i18n.T(locale, "dynamic.level_up", token.UserID, level.Level),
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to announce the level up of token %s: %v", token.TokenID, err)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// embedTemplate describes an embed in the config. Every string is a text/template rendered with embedData,
+// and {{t "key"}} looks up a message of the i18n catalog in the reply language.
+type embedTemplate struct {
+	Title       string               `mapstructure:"title"`
+	Description string               `mapstructure:"description"`
+	URL         string               `mapstructure:"url"`
+	Color       string               `mapstructure:"color"` // hex such as "#ff00ff"
+	Thumbnail   string               `mapstructure:"thumbnail"`
+	Image       string               `mapstructure:"image"`
+	Footer      string               `mapstructure:"footer"`
+	Author      embedAuthorTemplate  `mapstructure:"author"`
+	Fields      []embedFieldTemplate `mapstructure:"fields"`
+}
+
+type embedAuthorTemplate struct {
+	Name    string `mapstructure:"name"`
+	URL     string `mapstructure:"url"`
+	IconURL string `mapstructure:"iconUrl"`
+}
+
+type embedFieldTemplate struct {
+	Name   string `mapstructure:"name"`
+	Value  string `mapstructure:"value"`
+	Inline bool   `mapstructure:"inline"`
+}
+
+// embedData holds the placeholders available to the embed templates.
+type embedData struct {
+	Campaign    string
+	Address     string
+	Contract    string
+	TokenID     string
+	Edition     string // token id of an ERC1155 edition
+	Quantity    string
+	Rarity      string // the drawn traits, one per line
+	Name        string
+	Image       string
+	ScanURL     string
+	ContractURL string
+	AddressURL  string
+	TxHash      string
+	TxURL       string
+	Time        string
+	Error       string
+	Advertise   string
+
+	locale discordgo.Locale
+}
+
+var defaultSuccessEmbed = embedTemplate{
+	Title:       `{{t "embed.success.title"}}`,
+	Description: `{{t "embed.success.description"}}`,
+	Image:       "{{.Image}}",
+	Author: embedAuthorTemplate{
+		Name: "NFTRainbow",
+		URL:  "https://docs.nftrainbow.xyz/",
+	},
+	Fields: []embedFieldTemplate{
+		{Name: `{{t "embed.field.time"}}`, Value: "{{.Time}}", Inline: true},
+		{Name: `{{t "embed.field.contract"}}`, Value: "{{if .ContractURL}}[{{.Contract}}]({{.ContractURL}}){{else}}{{.Contract}}{{end}}", Inline: true},
+		{Name: `{{t "embed.field.token_id"}}`, Value: "{{if not .Edition}}{{.TokenID}}{{end}}", Inline: true},
+		{Name: `{{t "embed.field.edition"}}`, Value: "{{.Edition}}", Inline: true},
+		{Name: `{{t "embed.field.quantity"}}`, Value: "{{.Quantity}}", Inline: true},
+		{Name: `{{t "embed.field.rarity"}}`, Value: "{{.Rarity}}"},
+		{Name: `{{t "embed.field.nft_url"}}`, Value: `{{if .ScanURL}}[{{t "embed.scan_link"}}]({{.ScanURL}}){{end}}`},
+		{Name: `{{t "embed.field.tx"}}`, Value: `{{if .TxURL}}[{{t "embed.tx_link"}}]({{.TxURL}}){{end}}`},
+		{Name: `{{t "embed.field.advertise"}}`, Value: "{{.Advertise}}"},
+	},
+}
+
+var defaultFailEmbed = embedTemplate{
+	Title:       `{{t "embed.fail.title"}}`,
+	Description: `{{t "embed.fail.description"}}`,
+	Author: embedAuthorTemplate{
+		Name: "NFTRainbow",
+		URL:  "https://docs.nftrainbow.xyz/",
+	},
+	Fields: []embedFieldTemplate{
+		{Name: `{{t "embed.field.error"}}`, Value: "{{.Error}}"},
+		{Name: `{{t "embed.field.advertise"}}`, Value: "{{.Advertise}}"},
+	},
+}
+
+func successfulMessageEmbed(locale discordgo.Locale, campaign string, resp *models.MintResp) []*discordgo.MessageEmbed {
+	data := newEmbedData(locale, campaign)
+	data.Address = resp.UserAddress
+	data.Contract = resp.Contract
+	data.TokenID = resp.TokenID
+	if resp.Amount != "" {
+		data.Edition = resp.TokenID
+		data.Quantity = resp.Amount
+	}
+	var rarity []string
+	for _, trait := range resp.Traits {
+		// traits of pre-made NFTs have no chance
+		if trait.Chance == 0 {
+			rarity = append(rarity, fmt.Sprintf("**%s**: %s", trait.TraitType, trait.Value))
+			continue
+		}
+		rarity = append(rarity, fmt.Sprintf("**%s**: %s (%s%%)", trait.TraitType, trait.Value, strconv.FormatFloat(trait.Chance, 'f', -1, 64)))
+	}
+	data.Rarity = strings.Join(rarity, "\n")
+	data.Name = resp.Name
+	data.Image = resp.Image
+	data.ScanURL = resp.NFTAddress
+	data.TxHash = resp.TxHash
+	data.TxURL = resp.TxURL
+	data.Time = resp.Time
+
+	explorer := utils.ExplorerByChain(resp.Chain)
+	data.ContractURL = explorer.Contract(resp.Contract)
+	// masked addresses cannot be linked
+	if !strings.Contains(resp.UserAddress, "...") {
+		data.AddressURL = explorer.Address(resp.UserAddress)
+	}
+
+	return []*discordgo.MessageEmbed{loadEmbedTemplate(campaign, "success", defaultSuccessEmbed).render(data)}
+}
+
+func failMessageEmbed(locale discordgo.Locale, campaign, message string) []*discordgo.MessageEmbed {
+	data := newEmbedData(locale, campaign)
+	data.Error = message
+
+	return []*discordgo.MessageEmbed{loadEmbedTemplate(campaign, "fail", defaultFailEmbed).render(data)}
+}
+
+func newEmbedData(locale discordgo.Locale, campaign string) *embedData {
+	data := &embedData{
+		Advertise: viper.GetString("advertise"),
+		locale:    locale,
+	}
+	if campaign != "" {
+		data.Campaign = viper.GetString(campaign + ".campaignName")
+		if data.Campaign == "" {
+			data.Campaign = viper.GetString(campaign + ".name")
+		}
+	}
+	return data
+}
+
+// loadEmbedTemplate picks "<campaign>.embeds.<name>" over the global "embeds.<name>", and falls back to the built-in template.
+func loadEmbedTemplate(campaign, name string, fallback embedTemplate) embedTemplate {
+	keys := []string{"embeds." + name}
+	if campaign != "" {
+		keys = append([]string{campaign + ".embeds." + name}, keys...)
+	}
+	for _, key := range keys {
+		if !viper.IsSet(key) {
+			continue
+		}
+		var t embedTemplate
+		if err := viper.UnmarshalKey(key, &t); err != nil {
+			log.Printf("Invalid embed template %s: %v", key, err)
+			continue
+		}
+		return t
+	}
+	return fallback
+}
+
+func (t embedTemplate) render(data *embedData) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       renderText(t.Title, data),
+		Description: renderText(t.Description, data),
+		URL:         renderText(t.URL, data),
+		Color:       parseColor(t.Color),
+	}
+	if thumbnail := renderText(t.Thumbnail, data); thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: thumbnail}
+	}
+	if image := renderText(t.Image, data); image != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: image}
+	}
+	if footer := renderText(t.Footer, data); footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	}
+	if author := renderText(t.Author.Name, data); author != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{
+			Name:    author,
+			URL:     renderText(t.Author.URL, data),
+			IconURL: renderText(t.Author.IconURL, data),
+		}
+	}
+	for _, field := range t.Fields {
+		name, value := renderText(field.Name, data), renderText(field.Value, data)
+		// discord rejects fields with an empty name or value
+		if name == "" || value == "" {
+			continue
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   name,
+			Value:  value,
+			Inline: field.Inline,
+		})
+	}
+	return embed
+}
+
+func renderText(text string, data *embedData) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := template.New("embed").Funcs(template.FuncMap{
+		"t": func(key string, args ...interface{}) string {
+			return i18n.T(data.locale, key, args...)
+		},
+	}).Parse(text)
+	if err != nil {
+		log.Printf("Invalid embed template %q: %v", text, err)
+		return text
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		log.Printf("Failed to render embed template %q: %v", text, err)
+		return text
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func parseColor(color string) int {
+	if color == "" {
+		return 0
+	}
+	val, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		log.Printf("Invalid embed color %q: %v", color, err)
+		return 0
+	}
+	return int(val)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// errorCategory is what the user is told about a failure, the full error only goes to the logs.
+type errorCategory string
+
+const (
+	errInvalidAddress errorCategory = "invalid_address"
+	errWrongNetwork   errorCategory = "wrong_network"
+	errAlreadyClaimed errorCategory = "already_claimed"
+	errCampaignClosed errorCategory = "campaign_closed"
+	errSoldOut        errorCategory = "sold_out"
+	// the campaign is misconfigured, e.g. its contract failed the preflight
+	errCampaignUnavailable errorCategory = "campaign_unavailable"
+	errUpstream            errorCategory = "upstream_outage"
+	errInternal            errorCategory = "internal"
+)
+
+// claimError tags an error with its category. The message shown to the user is the catalog entry "error.<category>"
+// unless key is set.
+type claimError struct {
+	category errorCategory
+	key      string
+	err      error
+}
+
+func newClaimError(category errorCategory, err error) error {
+	return &claimError{category: category, err: err}
+}
+
+func (e *claimError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return i18n.T(i18n.DefaultLocale, e.messageKey())
+}
+
+func (e *claimError) Unwrap() error {
+	return e.err
+}
+
+func (e *claimError) messageKey() string {
+	if e.key != "" {
+		return e.key
+	}
+	return "error." + string(e.category)
+}
+
+// classifyError finds the category of an error which was not tagged when it was returned.
+func classifyError(err error) *claimError {
+	var ce *claimError
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	var (
+		urlErr       *url.Error
+		netErr       net.Error
+		syntaxErr    *json.SyntaxError
+		unmarshalErr *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.Is(err, utils.ErrWrongNetwork):
+		return &claimError{category: errWrongNetwork, err: err}
+	case errors.Is(err, utils.ErrInvalidAddress):
+		return &claimError{category: errInvalidAddress, err: err}
+	case errors.As(err, &urlErr), errors.As(err, &netErr), errors.As(err, &syntaxErr), errors.As(err, &unmarshalErr):
+		return &claimError{category: errUpstream, err: err}
+	default:
+		return &claimError{category: errInternal, err: err}
+	}
+}
+
+// reportError logs the full error under a reference id, forwards it to the admin log channel and returns the
+// friendly message for the user.
+func reportError(s *discordgo.Session, i *discordgo.InteractionCreate, locale discordgo.Locale, campaign string, err error) string {
+	ce := classifyError(err)
+	ref := newReferenceID()
+	user := interactionUser(i)
+
+	log.Printf("[%s] %s failed for %s (%s): %v", ref, campaign, user.ID, ce.category, err)
+
+	sendAdminLog(s, &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: "Claim failed",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Reference", Value: ref, Inline: true},
+			{Name: "Category", Value: string(ce.category), Inline: true},
+			{Name: "Campaign", Value: orDash(campaign), Inline: true},
+			{Name: "User", Value: fmt.Sprintf("<@%s>", user.ID), Inline: true},
+			{Name: "Channel", Value: fmt.Sprintf("<#%s>", i.ChannelID), Inline: true},
+			{Name: "Error", Value: truncate(err.Error(), 1024)},
+		},
+	})
+
+	return i18n.T(locale, ce.messageKey()) + "\n" + i18n.T(locale, "error.reference", ref)
+}
+
+// sendAdminLog posts to the channel configured by adminLogChannel, if any.
+func sendAdminLog(s *discordgo.Session, embed *discordgo.MessageEmbed) {
+	channel := viper.GetString("adminLogChannel")
+	if channel == "" {
+		return
+	}
+	if _, err := s.ChannelMessageSendEmbed(channel, embed); err != nil {
+		log.Printf("Failed to send admin log: %v", err)
+	}
+}
+
+func newReferenceID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// truncate shortens the text to max characters, the unit of the Discord embed limits, never splitting a character.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func orDash(text string) string {
+	if text == "" {
+		return "-"
+	}
+	return text
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/bwmarrin/discordgo"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nft-rainbow/discordBot/chain"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// maxFeedEpochs bounds the epoch range of a single cfx_getLogs call, public nodes reject larger ones.
+const maxFeedEpochs = 1000
+
+// maxFeedAttempts is how many polls try to post a log before it is skipped, so one log Discord keeps rejecting does
+// not hold the feed forever.
+const maxFeedAttempts = 3
+
+// feedAttempts counts the failed posts of each log by its feedLogID.
+var feedAttempts = make(map[string]int)
+
+// runTransferFeed polls the Transfer logs of the campaign contracts and posts them to feed.channelId. The cursor of
+// each chain is persisted after every posted log, so a restart resumes without gaps or duplicates. A log which fails
+// to post is retried by the next polls, and skipped after maxFeedAttempts.
+func runTransferFeed(s *discordgo.Session) {
+	if !viper.GetBool("feed.enabled") {
+		return
+	}
+	interval := viper.GetDuration("feed.interval")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	for {
+		for chainName, contracts := range feedContracts() {
+			if err := pollTransferFeed(s, chainName, contracts); err != nil {
+				log.Printf("Failed to poll the transfer feed of %s: %v", chainName, err)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// feedContracts groups the watched contracts by chain: the campaign contracts and the extra feed.contracts.
+func feedContracts() map[string][]string {
+	result := make(map[string][]string)
+	add := func(chainName, contract string) {
+		if contract == "" {
+			return
+		}
+		if chainType, _, err := utils.ChainInfoByName(chainName); err != nil || chainType != utils.CHAIN_TYPE_CFX {
+			return
+		}
+		address, err := utils.NormalizeAddress(chainName, contract)
+		if err != nil {
+			log.Printf("Invalid feed contract %s: %v", contract, err)
+			return
+		}
+		for _, c := range result[chainName] {
+			if c == address {
+				return
+			}
+		}
+		result[chainName] = append(result[chainName], address)
+	}
+	add(campaignChain("easyMint"), viper.GetString("easyMint.contract"))
+	add(campaignChain("customMint"), viper.GetString("customMint.contractAddress"))
+	for _, contract := range viper.GetStringSlice("feed.contracts") {
+		add(campaignChain("feed"), contract)
+	}
+	return result
+}
+
+func pollTransferFeed(s *discordgo.Session, chainName string, contracts []string) error {
+	client, err := chain.NewClient(chainName)
+	if err != nil {
+		return err
+	}
+	latest, err := chain.LatestConfirmedEpoch(client)
+	if err != nil {
+		return err
+	}
+	cursor, err := database.GetFeedCursor(chainName)
+	if err != nil {
+		return err
+	}
+	if cursor == nil {
+		// the first run starts from now instead of replaying the whole history
+		cursor = &database.FeedCursor{Epoch: latest + 1}
+		return database.SetFeedCursor(chainName, cursor)
+	}
+	if cursor.Epoch > latest {
+		return nil
+	}
+
+	to := latest
+	if to-cursor.Epoch >= maxFeedEpochs {
+		to = cursor.Epoch + maxFeedEpochs - 1
+	}
+	logs, err := chain.GetTransferLogs(client, contracts, cursor.Epoch, to)
+	if err != nil {
+		return err
+	}
+
+	holders, err := linkedHolders()
+	if err != nil {
+		return err
+	}
+	posted := make(map[string]bool)
+	for _, id := range cursor.Posted {
+		posted[id] = true
+	}
+	next := &database.FeedCursor{Epoch: cursor.Epoch, Posted: cursor.Posted}
+	index := 0
+	for _, l := range logs {
+		epoch := l.EpochNumber.ToInt().Uint64()
+		if epoch != next.Epoch {
+			next = &database.FeedCursor{Epoch: epoch}
+		}
+		id := feedLogID(l)
+		if epoch == cursor.Epoch {
+			index++
+			// cursors kept before Posted counted the posted logs of the epoch instead
+			if index <= cursor.Skip {
+				next.Posted = append(next.Posted, id)
+				continue
+			}
+			if posted[id] {
+				continue
+			}
+		}
+
+		if err = postTransfer(s, client, chainName, holders, l); err != nil {
+			feedAttempts[id]++
+			if feedAttempts[id] < maxFeedAttempts {
+				return err
+			}
+			log.Printf("Skipping the transfer %s of %s after %d attempts: %v", id, chainName, feedAttempts[id], err)
+			sendAdminLog(s, &discordgo.MessageEmbed{
+				Type:        discordgo.EmbedTypeRich,
+				Title:       "Transfer not posted",
+				Description: fmt.Sprintf("The transfer could not be posted to the feed after %d attempts and is skipped.", feedAttempts[id]),
+				Fields: []*discordgo.MessageEmbedField{
+					{Name: "Chain", Value: chainName, Inline: true},
+					{Name: "Log", Value: id},
+					{Name: "Error", Value: truncate(err.Error(), 1024)},
+				},
+			})
+		}
+		delete(feedAttempts, id)
+		next.Posted = append(next.Posted, id)
+		if err = database.SetFeedCursor(chainName, next); err != nil {
+			return err
+		}
+	}
+	return database.SetFeedCursor(chainName, &database.FeedCursor{Epoch: to + 1})
+}
+
+// feedLogID identifies a log by its transaction and its index in the transaction.
+func feedLogID(l types.Log) string {
+	id := ""
+	if l.TransactionHash != nil {
+		id = string(*l.TransactionHash)
+	}
+	if l.TransactionLogIndex != nil {
+		id += "/" + l.TransactionLogIndex.ToInt().String()
+	}
+	return id
+}
+
+// linkedHolders maps the hex body of each wallet verified with /wallet verify to its discord user. The addresses typed
+// into a claim prove nothing, so they are masked like any other wallet.
+func linkedHolders() (map[common.Address]string, error) {
+	wallets, err := database.GetVerifiedWallets()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[common.Address]string)
+	for userID, address := range wallets {
+		if addr, err := chain.CommonAddress(address); err == nil {
+			result[addr] = userID
+		}
+	}
+	return result, nil
+}
+
+func postTransfer(s *discordgo.Session, client *sdk.Client, chainName string, holders map[common.Address]string, l types.Log) error {
+	transfer := chain.ParseTransfer(l)
+	if transfer == nil {
+		return nil
+	}
+	if viper.GetBool("feed.mintsOnly") && !transfer.IsMint() {
+		return nil
+	}
+
+	_, chainId, _ := utils.ChainInfoByName(chainName)
+	explorer := utils.ExplorerByChain(chainName)
+	// the feed channel is shared, so it is posted in the default locale
+	locale := i18n.Pick()
+	privacy := loadPrivacy("feed")
+	// the feed channel is always public
+	privacy.Ephemeral = false
+	holder := func(addr common.Address) string {
+		if userID, ok := holders[addr]; ok {
+			return fmt.Sprintf("<@%s>", userID)
+		}
+		base32, err := cfxaddress.NewFromCommon(addr, uint32(chainId))
+		if err != nil {
+			return addr.Hex()
+		}
+		return fmt.Sprintf("[%s](%s)", privacy.address(base32.String()), explorer.Address(base32.String()))
+	}
+
+	contract := l.Address.String()
+	tokenId := transfer.TokenId.String()
+	embed := &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: i18n.T(locale, "feed.transfer"),
+		URL:   explorer.NFT(contract, tokenId),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: i18n.T(locale, "embed.field.token"), Value: fmt.Sprintf("%s #%s", contract, tokenId)},
+			{Name: i18n.T(locale, "embed.field.from"), Value: holder(transfer.From), Inline: true},
+			{Name: i18n.T(locale, "embed.field.to"), Value: holder(transfer.To), Inline: true},
+		},
+	}
+	if transfer.IsMint() {
+		embed.Title = i18n.T(locale, "feed.mint")
+		embed.Fields = append(embed.Fields[:1], embed.Fields[2])
+	}
+	if transfer.Amount.Cmp(common.Big1) != 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: i18n.T(locale, "embed.field.quantity"), Value: transfer.Amount.String(), Inline: true})
+	}
+	if l.TransactionHash != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "embed.field.tx"),
+			Value: fmt.Sprintf("[%s](%s)", utils.MaskAddress(string(*l.TransactionHash)), explorer.Tx(string(*l.TransactionHash))),
+		})
+	}
+
+	_, err := s.ChannelMessageSendEmbed(viper.GetString("feed.channelId"), embed)
+	return err
+}
@@ -0,0 +1,298 @@
+package i18n
+
+import "github.com/bwmarrin/discordgo"
+
+// catalog holds the bot replies per locale. Command names and descriptions are written in English in the
+// command definitions, so only their translations live here.
+var catalog = map[discordgo.Locale]map[string]string{
+	discordgo.EnglishUS: {
+		"claim.start.custom-mint": "Start to mint using custom-mint model. Please wait patiently.",
+		"claim.start.easy-mint":   "Start to mint using easy-mint model. Please wait patiently.",
+
+		"error.invalid_address":      "The address is not valid. Please check it and try again.",
+		"error.wrong_network":        "The address belongs to another network. Please use an address of the network this campaign mints on.",
+		"error.already_claimed":      "This account has minted NFT",
+		"error.minting":              "This account is minting NFT, please wait for the result.",
+		"error.sold_out":             "This campaign has run out of supply.",
+		"error.campaign_closed":      "This campaign is closed.",
+		"error.campaign_unavailable": "This campaign is not available right now. The admins have been notified.",
+		"error.upstream_outage":      "The minting service is temporarily unavailable. Please try again later.",
+		"error.internal":             "Something went wrong on our side. The admins have been notified.",
+		"error.reference":            "Reference ID: %s",
+
+		"embed.success.title":       ":rainbow: Mint NFT successfully  :rainbow:",
+		"embed.success.description": "Congratulate on minting NFT successfully! The NFT information is showed in the following.",
+		"embed.fail.title":          ":scream: Failed to Mint NFT  :scream:",
+		"embed.fail.description":    "There is problem during minting NFT. ",
+		"embed.field.time":          "Mints Time",
+		"embed.field.contract":      "Contract",
+		"embed.field.token_id":      "Token ID",
+		"embed.field.nft_url":       "NFT URL",
+		"embed.field.advertise":     "Advertise",
+		"embed.field.error":         "Error message",
+		"embed.scan_link":           "VIEW IN CONFLUX SCAN",
+		"embed.field.tx":            "Transaction",
+		"embed.field.edition":       "Edition",
+		"embed.field.quantity":      "Quantity",
+		"embed.field.rarity":        "Rarity",
+		"embed.field.level":         "Level",
+		"embed.field.rank":          "Rank",
+		"embed.field.token":         "Token",
+		"embed.field.from":          "From",
+		"embed.field.to":            "To",
+		"feed.transfer":             ":arrows_counterclockwise: Transfer",
+		"feed.mint":                 ":sparkles: New mint",
+		"embed.tx_link":             "VIEW TRANSACTION",
+
+		"mynfts.no_address": "No address is linked to your account yet. Please claim an NFT first or pass the user_address option.",
+		"mynfts.title":      ":rainbow: My NFTs :rainbow:",
+		"mynfts.empty":      "No NFTs minted by this bot were found for `%s`.",
+		"mynfts.header":     "Address: `%s`\nContract: `%s`",
+		"mynfts.page":       "Page %d/%d",
+		"mynfts.previous":   "Previous",
+		"mynfts.next":       "Next",
+		"mynfts.final":      "Confirmed on chain",
+
+		"wallet.link":              "Sign this message with your wallet (personal_sign), then send the signature with `/wallet verify` within %[2]d minutes:\n```\n%[1]s\n```",
+		"wallet.no_pending":        "There is no message waiting for a signature, or it has expired. Please run `/wallet link` first.",
+		"wallet.invalid_signature": "The signature does not match the message and the address.",
+		"wallet.verified":          "The wallet `%s` is verified, the token gated roles will follow its holdings.",
+
+		"privacy.dm_sent":          "The details have been sent to you by DM.",
+		"admin.not_allowed":        "Only server admins can use this command.",
+		"admin.artwork.updated":    "The artwork of %s is updated, new claims get it from now on.",
+		"admin.upload.too_large":   "The file is too large, at most %d MB can be uploaded.",
+		"admin.upload.unsupported": "The file cannot be uploaded (%s).",
+		"admin.release.done":       "The unminted item of `%s` is back in the pool of %s.",
+		"admin.release.none":       "`%s` holds no unminted item in the pool of %s.",
+		"admin.reveal.disabled":    "%s is not minted with placeholder metadata, set reveal.enabled to use it.",
+		"admin.reveal.nothing":     "No NFT of %s is waiting to be revealed.",
+		"admin.reveal.done":        "%d NFTs of %s are revealed, %d failed. Reveal it again to retry the failed ones.",
+		"admin.reveal.pending":     "%d claims were still minting, reveal it again once they are done to reveal them too.",
+
+		"reveal.announcement": ":sparkles: %s is revealed! %d NFTs now show their real artwork.",
+		"reveal.dm":           ":sparkles: Your %s NFT is revealed!",
+
+		"dynamic.level_up": ":arrow_up: <@%s>'s NFT has reached level %d!",
+	},
+	discordgo.ChineseCN: {
+		"command.claim.name":                                 "领取",
+		"command.claim.description":                          "领取 NFT 的命令",
+		"command.claim.custom-mint.name":                     "自定义铸造",
+		"command.claim.custom-mint.description":              "通过管理员部署的合约铸造 NFT",
+		"command.claim.custom-mint.user_address.name":        "用户地址",
+		"command.claim.custom-mint.user_address.description": "用户的钱包地址",
+		"command.claim.easy-mint.name":                       "快速铸造",
+		"command.claim.easy-mint.description":                "通过 NFTRainbow 的 NFTfactory 合约铸造 NFT",
+		"command.claim.easy-mint.user_address.name":          "用户地址",
+		"command.claim.easy-mint.user_address.description":   "用户的钱包地址",
+		"command.mynfts.name":                                "我的nft",
+		"command.mynfts.description":                         "列出本机器人铸造到你地址的 NFT",
+		"command.mynfts.user_address.name":                   "用户地址",
+		"command.mynfts.user_address.description":            "要查询的地址，默认为你领取时使用的地址",
+
+		"claim.start.custom-mint": "开始以自定义铸造模式铸造，请耐心等待。",
+		"claim.start.easy-mint":   "开始以快速铸造模式铸造，请耐心等待。",
+
+		"error.invalid_address":      "该地址无效，请检查后重试。",
+		"error.wrong_network":        "该地址属于其他网络，请使用本活动所在网络的地址。",
+		"error.already_claimed":      "该账户已经铸造过 NFT",
+		"error.minting":              "该账户正在铸造 NFT，请等待结果。",
+		"error.sold_out":             "本活动的 NFT 已全部领完。",
+		"error.campaign_closed":      "本活动已结束。",
+		"error.campaign_unavailable": "本活动暂时不可用，已通知管理员。",
+		"error.upstream_outage":      "铸造服务暂时不可用，请稍后再试。",
+		"error.internal":             "我们这边出了点问题，已通知管理员。",
+		"error.reference":            "错误编号：%s",
+
+		"embed.success.title":       ":rainbow: NFT 铸造成功  :rainbow:",
+		"embed.success.description": "恭喜你成功铸造 NFT！NFT 信息如下。",
+		"embed.fail.title":          ":scream: NFT 铸造失败  :scream:",
+		"embed.fail.description":    "铸造 NFT 的过程中出现了问题。",
+		"embed.field.time":          "铸造时间",
+		"embed.field.contract":      "合约",
+		"embed.field.token_id":      "Token ID",
+		"embed.field.nft_url":       "NFT 链接",
+		"embed.field.advertise":     "广告",
+		"embed.field.error":         "错误信息",
+		"embed.field.tx":            "交易",
+		"embed.field.edition":       "版本",
+		"embed.field.quantity":      "数量",
+		"embed.field.rarity":        "稀有度",
+		"embed.scan_link":           "在 CONFLUX SCAN 中查看",
+		"embed.tx_link":             "查看交易",
+
+		"mynfts.no_address":                            "你的账户还没有关联地址，请先领取 NFT 或者填写 user_address 参数。",
+		"mynfts.title":                                 ":rainbow: 我的 NFT :rainbow:",
+		"mynfts.empty":                                 "没有找到本机器人铸造到 `%s` 的 NFT。",
+		"mynfts.header":                                "地址：`%s`\n合约：`%s`",
+		"mynfts.page":                                  "第 %d/%d 页",
+		"mynfts.previous":                              "上一页",
+		"mynfts.next":                                  "下一页",
+		"mynfts.final":                                 "已在链上确认",
+		"wallet.link":                                  "请用你的钱包签名（personal_sign）以下消息，并在 %[2]d 分钟内通过 `/wallet verify` 发送签名：\n```\n%[1]s\n```",
+		"wallet.no_pending":                            "没有等待签名的消息，或者消息已过期，请先使用 `/wallet link`。",
+		"wallet.invalid_signature":                     "签名与消息和地址不匹配。",
+		"wallet.verified":                              "钱包 `%s` 已验证，身份组将根据其持有的 NFT 更新。",
+		"command.wallet.name":                          "钱包",
+		"command.wallet.description":                   "证明你拥有某个钱包，用于持币身份组",
+		"command.wallet.link.name":                     "关联",
+		"command.wallet.link.description":              "获取需要用钱包签名的消息",
+		"command.wallet.link.user_address.name":        "用户地址",
+		"command.wallet.link.user_address.description": "你的钱包地址",
+		"command.wallet.verify.name":                   "验证",
+		"command.wallet.verify.description":            "发送消息的签名",
+		"command.wallet.verify.signature.name":         "签名",
+		"command.wallet.verify.signature.description":  "钱包生成的签名",
+
+		"privacy.dm_sent":                                "详细信息已通过私信发送给你。",
+		"command.admin.name":                             "管理",
+		"command.admin.description":                      "管理活动，仅限服务器管理员",
+		"command.admin.artwork.name":                     "作品",
+		"command.admin.artwork.description":              "设置活动的 NFT 图片",
+		"command.admin.artwork.campaign.name":            "活动",
+		"command.admin.artwork.campaign.description":     "要更新的活动",
+		"command.admin.artwork.file.name":                "文件",
+		"command.admin.artwork.file.description":         "新的 NFT 图片",
+		"admin.not_allowed":                              "只有服务器管理员可以使用此命令。",
+		"admin.artwork.updated":                          "%s 的 NFT 图片已更新，之后的领取都会使用新图片。",
+		"admin.upload.too_large":                         "文件过大，最多可上传 %d MB。",
+		"admin.upload.unsupported":                       "无法上传该文件（%s）。",
+		"command.admin.reveal.name":                      "揭晓",
+		"command.admin.reveal.description":               "揭晓以占位元数据铸造的 NFT",
+		"command.admin.reveal.campaign.name":             "活动",
+		"command.admin.reveal.campaign.description":      "要揭晓的活动",
+		"command.admin.release.name":                     "释放",
+		"command.admin.release.description":              "将领取者未铸造的藏品放回藏品池",
+		"command.admin.release.campaign.name":            "活动",
+		"command.admin.release.campaign.description":     "藏品池所属的活动",
+		"command.admin.release.user_address.name":        "用户地址",
+		"command.admin.release.user_address.description": "藏品分配到的地址",
+		"admin.release.done":                             "`%s` 未铸造的藏品已放回 %s 的藏品池。",
+		"admin.release.none":                             "`%s` 在 %s 的藏品池中没有未铸造的藏品。",
+		"admin.reveal.disabled":                          "%s 未使用占位元数据铸造，请先设置 reveal.enabled。",
+		"admin.reveal.nothing":                           "%s 没有等待揭晓的 NFT。",
+		"admin.reveal.done":                              "已揭晓 %[2]s 的 %[1]d 个 NFT，%[3]d 个失败。再次揭晓即可重试失败的 NFT。",
+		"admin.reveal.pending":                           "有 %d 个领取仍在铸造中，完成后请再次揭晓。",
+		"reveal.announcement":                            ":sparkles: %s 已揭晓！%d 个 NFT 现在展示真正的作品。",
+		"reveal.dm":                                      ":sparkles: 你的 %s NFT 已揭晓！",
+		"dynamic.level_up":                               ":arrow_up: <@%s> 的 NFT 升到了 %d 级！",
+		"embed.field.level":                              "等级",
+		"embed.field.rank":                               "称号",
+		"embed.field.token":                              "代币",
+		"embed.field.from":                               "发送方",
+		"embed.field.to":                                 "接收方",
+		"feed.transfer":                                  ":arrows_counterclockwise: 转移",
+		"feed.mint":                                      ":sparkles: 新铸造",
+	},
+	discordgo.ChineseTW: {
+		"command.claim.name":                                 "領取",
+		"command.claim.description":                          "領取 NFT 的指令",
+		"command.claim.custom-mint.name":                     "自訂鑄造",
+		"command.claim.custom-mint.description":              "透過管理員部署的合約鑄造 NFT",
+		"command.claim.custom-mint.user_address.name":        "用戶地址",
+		"command.claim.custom-mint.user_address.description": "用戶的錢包地址",
+		"command.claim.easy-mint.name":                       "快速鑄造",
+		"command.claim.easy-mint.description":                "透過 NFTRainbow 的 NFTfactory 合約鑄造 NFT",
+		"command.claim.easy-mint.user_address.name":          "用戶地址",
+		"command.claim.easy-mint.user_address.description":   "用戶的錢包地址",
+		"command.mynfts.name":                                "我的nft",
+		"command.mynfts.description":                         "列出本機器人鑄造到你地址的 NFT",
+		"command.mynfts.user_address.name":                   "用戶地址",
+		"command.mynfts.user_address.description":            "要查詢的地址，預設為你領取時使用的地址",
+
+		"claim.start.custom-mint": "開始以自訂鑄造模式鑄造，請耐心等待。",
+		"claim.start.easy-mint":   "開始以快速鑄造模式鑄造，請耐心等待。",
+
+		"error.invalid_address":      "該地址無效，請檢查後重試。",
+		"error.wrong_network":        "該地址屬於其他網路，請使用本活動所在網路的地址。",
+		"error.already_claimed":      "該帳戶已經鑄造過 NFT",
+		"error.minting":              "該帳戶正在鑄造 NFT，請等待結果。",
+		"error.sold_out":             "本活動的 NFT 已全部領完。",
+		"error.campaign_closed":      "本活動已結束。",
+		"error.campaign_unavailable": "本活動暫時無法使用，已通知管理員。",
+		"error.upstream_outage":      "鑄造服務暫時無法使用，請稍後再試。",
+		"error.internal":             "我們這邊出了點問題，已通知管理員。",
+		"error.reference":            "錯誤編號：%s",
+
+		"embed.success.title":       ":rainbow: NFT 鑄造成功  :rainbow:",
+		"embed.success.description": "恭喜你成功鑄造 NFT！NFT 資訊如下。",
+		"embed.fail.title":          ":scream: NFT 鑄造失敗  :scream:",
+		"embed.fail.description":    "鑄造 NFT 的過程中出現了問題。",
+		"embed.field.time":          "鑄造時間",
+		"embed.field.contract":      "合約",
+		"embed.field.token_id":      "Token ID",
+		"embed.field.nft_url":       "NFT 連結",
+		"embed.field.advertise":     "廣告",
+		"embed.field.error":         "錯誤訊息",
+		"embed.field.tx":            "交易",
+		"embed.field.edition":       "版本",
+		"embed.field.quantity":      "數量",
+		"embed.field.rarity":        "稀有度",
+		"embed.scan_link":           "在 CONFLUX SCAN 中查看",
+		"embed.tx_link":             "查看交易",
+
+		"mynfts.no_address":                            "你的帳戶還沒有關聯地址，請先領取 NFT 或填寫 user_address 參數。",
+		"mynfts.title":                                 ":rainbow: 我的 NFT :rainbow:",
+		"mynfts.empty":                                 "沒有找到本機器人鑄造到 `%s` 的 NFT。",
+		"mynfts.header":                                "地址：`%s`\n合約：`%s`",
+		"mynfts.page":                                  "第 %d/%d 頁",
+		"mynfts.previous":                              "上一頁",
+		"mynfts.next":                                  "下一頁",
+		"mynfts.final":                                 "已在鏈上確認",
+		"wallet.link":                                  "請用你的錢包簽署（personal_sign）以下訊息，並在 %[2]d 分鐘內透過 `/wallet verify` 傳送簽章：\n```\n%[1]s\n```",
+		"wallet.no_pending":                            "沒有等待簽署的訊息，或者訊息已過期，請先使用 `/wallet link`。",
+		"wallet.invalid_signature":                     "簽章與訊息和地址不相符。",
+		"wallet.verified":                              "錢包 `%s` 已驗證，身分組將依其持有的 NFT 更新。",
+		"command.wallet.name":                          "錢包",
+		"command.wallet.description":                   "證明你擁有某個錢包，用於持幣身分組",
+		"command.wallet.link.name":                     "連結",
+		"command.wallet.link.description":              "取得需要用錢包簽署的訊息",
+		"command.wallet.link.user_address.name":        "用戶地址",
+		"command.wallet.link.user_address.description": "你的錢包地址",
+		"command.wallet.verify.name":                   "驗證",
+		"command.wallet.verify.description":            "傳送訊息的簽章",
+		"command.wallet.verify.signature.name":         "簽章",
+		"command.wallet.verify.signature.description":  "錢包產生的簽章",
+
+		"privacy.dm_sent":                                "詳細資訊已透過私訊傳送給你。",
+		"command.admin.name":                             "管理",
+		"command.admin.description":                      "管理活動，僅限伺服器管理員",
+		"command.admin.artwork.name":                     "作品",
+		"command.admin.artwork.description":              "設定活動的 NFT 圖片",
+		"command.admin.artwork.campaign.name":            "活動",
+		"command.admin.artwork.campaign.description":     "要更新的活動",
+		"command.admin.artwork.file.name":                "檔案",
+		"command.admin.artwork.file.description":         "新的 NFT 圖片",
+		"admin.not_allowed":                              "只有伺服器管理員可以使用此指令。",
+		"admin.artwork.updated":                          "%s 的 NFT 圖片已更新，之後的領取都會使用新圖片。",
+		"admin.upload.too_large":                         "檔案過大，最多可上傳 %d MB。",
+		"admin.upload.unsupported":                       "無法上傳該檔案（%s）。",
+		"command.admin.reveal.name":                      "揭曉",
+		"command.admin.reveal.description":               "揭曉以佔位元資料鑄造的 NFT",
+		"command.admin.reveal.campaign.name":             "活動",
+		"command.admin.reveal.campaign.description":      "要揭曉的活動",
+		"command.admin.release.name":                     "釋放",
+		"command.admin.release.description":              "將領取者未鑄造的藏品放回藏品池",
+		"command.admin.release.campaign.name":            "活動",
+		"command.admin.release.campaign.description":     "藏品池所屬的活動",
+		"command.admin.release.user_address.name":        "用戶地址",
+		"command.admin.release.user_address.description": "藏品分配到的地址",
+		"admin.release.done":                             "`%s` 未鑄造的藏品已放回 %s 的藏品池。",
+		"admin.release.none":                             "`%s` 在 %s 的藏品池中沒有未鑄造的藏品。",
+		"admin.reveal.disabled":                          "%s 未使用佔位元資料鑄造，請先設定 reveal.enabled。",
+		"admin.reveal.nothing":                           "%s 沒有等待揭曉的 NFT。",
+		"admin.reveal.done":                              "已揭曉 %[2]s 的 %[1]d 個 NFT，%[3]d 個失敗。再次揭曉即可重試失敗的 NFT。",
+		"admin.reveal.pending":                           "有 %d 個領取仍在鑄造中，完成後請再次揭曉。",
+		"reveal.announcement":                            ":sparkles: %s 已揭曉！%d 個 NFT 現在展示真正的作品。",
+		"reveal.dm":                                      ":sparkles: 你的 %s NFT 已揭曉！",
+		"dynamic.level_up":                               ":arrow_up: <@%s> 的 NFT 升到了 %d 級！",
+		"embed.field.level":                              "等級",
+		"embed.field.rank":                               "稱號",
+		"embed.field.token":                              "代幣",
+		"embed.field.from":                               "發送方",
+		"embed.field.to":                                 "接收方",
+		"feed.transfer":                                  ":arrows_counterclockwise: 轉移",
+		"feed.mint":                                      ":sparkles: 新鑄造",
+	},
+}
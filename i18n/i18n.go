@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/spf13/viper"
+)
+
+// DefaultLocale is used whenever a message has no translation for the requested locale.
+const DefaultLocale = discordgo.EnglishUS
+
+// T returns the message of key in the locale, falling back to English and at last to the key itself.
+// The args are applied with fmt.Sprintf when given.
+func T(locale discordgo.Locale, key string, args ...interface{}) string {
+	message, ok := catalog[locale][key]
+	if !ok {
+		message, ok = catalog[DefaultLocale][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Pick returns the first supported locale, then the configured defaultLocale, then English.
+func Pick(locales ...discordgo.Locale) discordgo.Locale {
+	locales = append(locales, discordgo.Locale(viper.GetString("defaultLocale")))
+	for _, locale := range locales {
+		if _, ok := catalog[locale]; ok {
+			return locale
+		}
+		if strings.HasPrefix(string(locale), "en-") {
+			return DefaultLocale
+		}
+	}
+	return DefaultLocale
+}
+
+// InteractionLocale picks the reply language from the user's client, then the guild default.
+func InteractionLocale(i *discordgo.InteractionCreate) discordgo.Locale {
+	locales := []discordgo.Locale{i.Locale}
+	if i.GuildLocale != nil {
+		locales = append(locales, *i.GuildLocale)
+	}
+	return Pick(locales...)
+}
+
+// LocalizeCommands fills in the name and description localizations of the commands and their options.
+// The catalog keys are "command.<name>[.<option>...].name" and "command.<name>[.<option>...].description".
+func LocalizeCommands(commands []*discordgo.ApplicationCommand) {
+	for _, command := range commands {
+		key := "command." + command.Name
+		if names := localizations(key + ".name"); len(names) > 0 {
+			command.NameLocalizations = &names
+		}
+		if descriptions := localizations(key + ".description"); len(descriptions) > 0 {
+			command.DescriptionLocalizations = &descriptions
+		}
+		localizeOptions(key, command.Options)
+	}
+}
+
+func localizeOptions(prefix string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		key := prefix + "." + option.Name
+		option.NameLocalizations = localizations(key + ".name")
+		option.DescriptionLocalizations = localizations(key + ".description")
+		localizeOptions(key, option.Options)
+	}
+}
+
+// localizations collects the translations of key in every locale except English, which is the command default.
+func localizations(key string) map[discordgo.Locale]string {
+	result := make(map[discordgo.Locale]string)
+	for locale, messages := range catalog {
+		if locale == DefaultLocale {
+			continue
+		}
+		if message, ok := messages[key]; ok && strings.TrimSpace(message) != "" {
+			result[locale] = message
+		}
+	}
+	return result
+}
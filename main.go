@@ -2,17 +2,21 @@ package main
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/fsnotify/fsnotify"
 	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
 	"github.com/nft-rainbow/discordBot/models"
 	"github.com/nft-rainbow/discordBot/service"
 	"github.com/nft-rainbow/discordBot/utils"
 	"github.com/spf13/viper"
 	"log"
+	"math/big"
 	"os"
 	"os/signal"
+	"strings"
+	"time"
 )
 var s *discordgo.Session
 
@@ -62,40 +66,55 @@ var (
 				},
 			},
 		},
+		{
+			Name:        "mynfts",
+			Description: "List the NFTs minted by this bot to your address",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user_address",
+					Description: "The address to look up, defaults to the address you claimed with",
+					Required:    false,
+				},
+			},
+		},
+		adminCommand,
+		walletCommand,
 	}
 
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 		"claim": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			options := i.ApplicationCommandData().Options
+			locale := i18n.InteractionLocale(i)
 			var resp *models.MintResp
 			userAddress := options[0].Options[0].Value.(string)
-			startFlag := ""
+			campaign := ""
 			var err error
 			switch options[0].Name {
 			case "custom-mint":
-				startFlag = "Start to mint using custom-mint model. Please wait patiently."
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: startFlag,
-					},
-				})
-				resp, err = handleCustomMint(userAddress)
+				campaign = "customMint"
 			case "easy-mint":
+				campaign = "easyMint"
+			}
+			privacy := loadPrivacy(campaign, "commands.claim")
 
-				startFlag = "Start to mint using easy-mint model. Please wait patiently."
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: startFlag,
-					},
-				})
-
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: i18n.T(locale, "claim.start."+options[0].Name),
+					Flags:   privacy.flags(),
+				},
+			})
+			switch campaign {
+			case "customMint":
+				resp, err = handleCustomMint(userAddress, newClaimant(s, i, campaign))
+			case "easyMint":
 				resp, err = handleEasyMint(userAddress)
 			}
 			if err != nil {
 				s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-					Embeds: failMessageEmbed(err.Error()),
+					Embeds: failMessageEmbed(locale, campaign, reportError(s, i, locale, campaign, err)),
+					Flags:  privacy.flags(),
 				})
 				return
 			}
@@ -106,15 +125,49 @@ var (
 			//	Disabled: false,
 			//}
 
+			_ = database.LinkUser(interactionUser(i).ID, resp.UserAddress)
+			_ = database.InsertMintRecord(resp.UserAddress, resp)
+
+			if privacy.DM {
+				// the details go by DM instead of the public message, which only acknowledges the claim
+				err = utils.SendDM(s, interactionUser(i).ID, &discordgo.MessageSend{
+					Embeds: successfulMessageEmbed(locale, campaign, resp),
+				})
+				if err == nil {
+					s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+						Content: i18n.T(locale, "privacy.dm_sent"),
+						Flags:   privacy.flags(),
+					})
+					return
+				}
+				// the user does not accept DMs, so only they get to see the full details
+				log.Printf("Failed to send DM to %s: %v", interactionUser(i).ID, err)
+				s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+					Embeds: successfulMessageEmbed(locale, campaign, resp),
+					Flags:  uint64(discordgo.MessageFlagsEphemeral),
+				})
+				return
+			}
+			public := *resp
+			public.UserAddress = privacy.address(resp.UserAddress)
 			s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
 				//Components: []discordgo.MessageComponent{
 				//	discordgo.ActionsRow{
 				//		Components: []discordgo.MessageComponent{button},
 				//	},
 				//},
-				Embeds: successfulMessageEmbed(resp),
+				Embeds: successfulMessageEmbed(locale, campaign, &public),
+				Flags:  privacy.flags(),
 			})
 		},
+		"mynfts": handleMyNFTs,
+		"admin":  handleAdmin,
+		"wallet": handleWallet,
+	}
+
+	// componentHandlers are keyed by the part of the custom id before the first colon.
+	componentHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"mynfts": handleMyNFTsPage,
 	}
 )
 
@@ -126,13 +179,49 @@ func init() {
 		log.Fatalf("Invalid bot parameters: %v", err)
 	}
 	database.ConnectDB()
+	migrateAddressKeys()
+}
+
+// migrateAddressKeys moves the claims and mint records kept under addresses as users typed them to the normalized
+// addresses they are looked up by. Normalized keys are left alone, so it only moves something once.
+func migrateAddressKeys() {
+	migrations := []struct {
+		bucket    []byte
+		normalize func(string) (string, error)
+	}{
+		{database.CustomMintBucket, func(address string) (string, error) {
+			return utils.NormalizeAddress(campaignChain("customMint"), address)
+		}},
+		{database.EasyMintBucket, func(address string) (string, error) {
+			return utils.NormalizeAddress(campaignChain("easyMint"), address)
+		}},
+		{database.MintRecordBucket, normalizeHolderAddress},
+	}
+	for _, m := range migrations {
+		moved, err := database.MigrateAddressKeys(m.bucket, m.normalize)
+		if err != nil {
+			log.Printf("Failed to normalize the addresses of %s: %v", m.bucket, err)
+			continue
+		}
+		if moved > 0 {
+			log.Printf("Normalized %d addresses of %s", moved, m.bucket)
+		}
+	}
 }
 
 
 func init() {
 	s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-			h(s, i)
+		switch i.Type {
+		case discordgo.InteractionApplicationCommand:
+			if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+				h(s, i)
+			}
+		case discordgo.InteractionMessageComponent:
+			prefix := strings.SplitN(i.MessageComponentData().CustomID, ":", 2)[0]
+			if h, ok := componentHandlers[prefix]; ok {
+				h(s, i)
+			}
 		}
 	})
 }
@@ -146,7 +235,30 @@ func main() {
 		log.Fatalf("Cannot open the session: %v", err)
 	}
 
+	go runMintVerifier(s)
+	go runTokenGate(s)
+	go runTransferFeed(s)
+	go runDynamicNFTs(s)
+	preflight := func() {
+		if viper.GetString("customMint.contractAddress") != "" {
+			_ = preflightCustomMint(s)
+		}
+		if poolEnabled("customMint") {
+			if err := loadCampaignPool("customMint"); err != nil {
+				log.Printf("Failed to load the pool of customMint: %v", err)
+			}
+		}
+	}
+	go preflight()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+		// the result is cached per contract settings, so this only checks again when they changed
+		preflight()
+	})
+	viper.WatchConfig()
+
 	log.Println("Adding commands...")
+	i18n.LocalizeCommands(commands)
 	registeredCommands := make([]*discordgo.ApplicationCommand, len(commands))
 	for i, v := range commands {
 		cmd, err := s.ApplicationCommandCreate(s.State.User.ID, "", v)
@@ -166,38 +278,60 @@ func main() {
 	log.Println("Gracefully shutting down.")
 }
 
+// interactionUser returns the user who triggered the interaction, whether it happened in a guild or in a DM.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// checkRestrain starts the claim of the address, marking it Minting, unless it claimed already or is claiming.
 func checkRestrain(address string, mintType []byte) error{
-	status, err := database.GetStatus(address, mintType)
+	status, err := database.StartClaim(address, mintType)
 	if err != nil {
 		return err
 	}
 
 	if bytes.Equal(status, []byte("Success")) {
-		return errors.New("This account has minted NFT")
+		return &claimError{category: errAlreadyClaimed}
 	}
 	if bytes.Equal(status, []byte("Minting")) {
-		return errors.New("This account is minting NFT")
+		return &claimError{category: errAlreadyClaimed, key: "error.minting"}
 	}
 
 	return nil
 }
 
-func handleCustomMint(userAddress string) (*models.MintResp, error){
+func handleCustomMint(userAddress string, claim *claimant) (*models.MintResp, error){
 	var err error
+	chain := campaignChain("customMint")
+	// only the claim which marked the address Minting may reset it
+	started := false
 	defer func() {
 		status, _ := database.GetStatus(userAddress, database.CustomMintBucket)
-		if err != nil && !bytes.Equal(status, []byte("Success")) {
+		if err != nil && started && !bytes.Equal(status, []byte("Success")) {
 			_ = database.InsertDB(userAddress, []byte("NoMinting"), database.CustomMintBucket)
 		}
 	}()
-	_, err = utils.CheckCfxAddress(utils.CONFLUX_TEST, userAddress)
+	address, err := utils.NormalizeAddress(chain, userAddress)
+	if err != nil {
+		return nil, err
+	}
+	userAddress = address
+	// a claimant holding an unminted item of the pool may retry after the pool ran out
+	if campaignClosed("customMint") && !poolItemHeld("customMint", userAddress) {
+		err = &claimError{category: errCampaignClosed}
+		return nil, err
+	}
+	err = preflightCustomMint(s)
 	if err != nil {
 		return nil, err
 	}
 
-	contractAddress := viper.GetString("customMint.contractAddress")
-	_, err = utils.CheckCfxAddress(utils.CONFLUX_TEST, contractAddress)
+	contractAddress, err := utils.NormalizeAddress(chain, viper.GetString("customMint.contractAddress"))
 	if err != nil {
+		err = newClaimError(errInternal, fmt.Errorf("invalid customMint.contractAddress: %w", err))
 		return nil, err
 	}
 
@@ -205,31 +339,178 @@ func handleCustomMint(userAddress string) (*models.MintResp, error){
 	if err != nil {
 		return nil, err
 	}
-	_ = database.InsertDB(userAddress, []byte("Minting"), database.CustomMintBucket)
+	started = true
+
+	edition, amount, err := customMintEdition()
+	if err != nil {
+		err = newClaimError(errInternal, err)
+		return nil, err
+	}
+	// the supply is counted in units, an ERC1155 claim takes the whole amount of the edition
+	units := uint64(1)
+	supplyKey := contractAddress
+	if edition != nil {
+		units = amount.Uint64()
+		supplyKey += "/" + edition.String()
+	}
+	maxSupply := viper.GetUint64("customMint.maxSupply")
+	used, err := database.ReserveSupply(supplyKey, units, maxSupply)
+	if err != nil {
+		err = newClaimError(errInternal, err)
+		return nil, err
+	}
+	if used == 0 {
+		err = &claimError{category: errSoldOut}
+		return nil, err
+	}
+	mintRequested := false
+	defer func() {
+		// once the mint was requested it may have landed, so its units and drawn traits stay counted
+		if err != nil && !mintRequested {
+			_ = database.ReleaseSupply(supplyKey, units)
+			_ = database.ReleaseRarity("customMint", userAddress)
+		}
+	}()
+
+	// a pool hands out pre-made NFTs, which are neither drawn nor rendered
+	var item *models.CollectionItem
+	if poolEnabled("customMint") {
+		item, err = takeCampaignPoolItem("customMint", userAddress)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			// once the mint was requested it may have landed, the item stays with the claimant for their retry
+			if err != nil && !mintRequested {
+				_, _ = releaseCampaignPoolItem("customMint", userAddress)
+			}
+		}()
+		if viper.GetBool("customMint.pool.tokenIds") {
+			tokenId, ok := new(big.Int).SetString(item.ID, 10)
+			if !ok {
+				err = newClaimError(errInternal, fmt.Errorf("pool item %s has no numeric id to use as token id", item.ID))
+				return nil, err
+			}
+			edition = tokenId
+		}
+	} else {
+		claim.Traits, err = drawCampaignTraits("customMint", userAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	token, err := service.Login()
 	if err != nil {
+		err = newClaimError(errUpstream, err)
 		return nil, err
 	}
 
-	metadataUri, err := service.CreateMetadata(token, viper.GetString("customMint.fileUrl"), viper.GetString("customMint.name"), viper.GetString("customMint.description"))
+	claim.Serial, err = database.NextSerial(supplyKey, units)
 	if err != nil {
+		err = newClaimError(errInternal, err)
+		return nil, err
+	}
+	claim.MaxSupply = maxSupply / units
+	metadataUri := ""
+	if (revealPending("customMint") || dynamicEnabled("customMint")) && edition != nil && item == nil {
+		err = newClaimError(errInternal, fmt.Errorf("the claimants of an erc1155 edition share its metadata, a reveal or dynamic tokens need customMint.pool.tokenIds"))
 		return nil, err
 	}
+	if item != nil {
+		metadataUri = item.MetadataUri
+	} else {
+		claim.Image, err = campaignBadge(token, "customMint", claim)
+		if err != nil {
+			return nil, err
+		}
+		metadataUri, err = campaignMetadataURI(token, "customMint", claim)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// until the reveal the token shows a placeholder of its own, which the reveal updates to the final metadata
+	var revealEntry *database.RevealEntry
+	var placeholder *models.Metadata
+	if revealPending("customMint") {
+		var res *models.CreateMetadataResponse
+		res, placeholder, err = campaignPlaceholder(token, "customMint")
+		if err != nil {
+			return nil, err
+		}
+		revealEntry = &database.RevealEntry{
+			Address: userAddress,
+			UserID: claim.UserID,
+			PlaceholderID: res.MetadataID,
+			PlaceholderURI: res.MetadataURI,
+			FinalURI: metadataUri,
+		}
+		metadataUri = res.MetadataURI
+	}
+	// dynamic tokens level up with the activity of their holder
+	var dynamicToken *database.DynamicToken
+	if dynamicEnabled("customMint") {
+		dynamicToken, metadataUri, err = campaignDynamicToken(token, metadataUri, revealEntry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the entry is recorded before the mint, so the reveal finds the token even when its claim fails afterwards
+	if revealEntry != nil {
+		revealEntry.Contract = contractAddress
+		revealEntry.RequestedAt = time.Now()
+		err = database.PutRevealEntry("customMint", revealEntry)
+		if err != nil {
+			err = newClaimError(errInternal, err)
+			return nil, err
+		}
+	}
+	mintRequested = true
 	resp , err := service.SendCustomMintRequest(token, models.CustomMintDto{
-		models.ContractInfoDto{
-			Chain: viper.GetString("chainType"),
+		ContractInfoDto: models.ContractInfoDto{
+			Chain: chain,
 			ContractType: viper.GetString("customMint.contractType"),
 			ContractAddress: contractAddress,
 		},
-		models.MintItemDto{
+		MintItemDto: models.MintItemDto{
 			MintToAddress: userAddress,
+			TokenId: edition,
+			Amount: amount,
 			MetadataUri: metadataUri,
 		},
 	})
 	if err != nil {
+		err = newClaimError(errUpstream, err)
 		return nil, err
 	}
+	if amount != nil {
+		resp.Amount = amount.String()
+	}
+	resp.Traits = claim.Traits
+	resp.Name = viper.GetString("customMint.name")
+	resp.Image = campaignFileURL("customMint")
+	if claim.Image != "" {
+		resp.Image = claim.Image
+	}
+	if item != nil {
+		poolItemMinted(s, "customMint", userAddress, resp.TokenID)
+		resp.Name = item.Name
+		resp.Image = item.FileUrl
+		resp.Traits = item.Traits
+	}
+	if revealEntry != nil {
+		recordRevealToken("customMint", revealEntry, resp.TokenID)
+		resp.Name = placeholder.Name
+		resp.Image = placeholder.Image
+		resp.Traits = nil
+	}
+	if dynamicToken != nil {
+		dynamicToken.Contract = contractAddress
+		dynamicToken.TokenID = resp.TokenID
+		dynamicToken.Address = userAddress
+		dynamicToken.UserID = claim.UserID
+		recordDynamicToken("customMint", dynamicToken)
+	}
 	_ = database.InsertDB(userAddress, []byte("Success"), database.CustomMintBucket)
 
 	return resp, err
@@ -237,128 +518,67 @@ func handleCustomMint(userAddress string) (*models.MintResp, error){
 
 func handleEasyMint(userAddress string)(*models.MintResp, error) {
 	var err error
+	chain := campaignChain("easyMint")
+	started := false
 	defer func() {
 		status, _ := database.GetStatus(userAddress, database.EasyMintBucket)
-		if err != nil && !bytes.Equal(status, []byte("Success")) {
+		if err != nil && started && !bytes.Equal(status, []byte("Success")) {
 			_ = database.InsertDB(userAddress, []byte("NoMinting"), database.EasyMintBucket)
 		}
 	}()
-	_, err = utils.CheckCfxAddress(utils.CONFLUX_TEST, userAddress)
+	if campaignClosed("easyMint") {
+		err = &claimError{category: errCampaignClosed}
+		return nil, err
+	}
+	address, err := utils.NormalizeAddress(chain, userAddress)
 	if err != nil {
 		return nil, err
 	}
+	userAddress = address
 	err = checkRestrain(userAddress, database.EasyMintBucket)
 	if err != nil {
 		return nil, err
 	}
-	_ = database.InsertDB(userAddress, []byte("Minting"), database.EasyMintBucket)
+	started = true
 
 	token, err := service.Login()
 	if err != nil {
+		err = newClaimError(errUpstream, err)
 		return nil, err
 	}
 
 	resp , err := service.SendEasyMintRequest(token, models.EasyMintMetaDto{
-		Chain: viper.GetString("chainType"),
+		Chain: chain,
 		Name: viper.GetString("easyMint.name"),
 		Description: viper.GetString("easyMint.description"),
 		MintToAddress: userAddress,
-		FileUrl: viper.GetString("easyMint.fileUrl"),
+		FileUrl: campaignFileURL("easyMint"),
 	})
 	if err != nil {
+		err = newClaimError(errUpstream, err)
 		return nil, err
 	}
+	resp.Name = viper.GetString("easyMint.name")
+	resp.Image = campaignFileURL("easyMint")
 	_ = database.InsertDB(userAddress, []byte("Success"), database.EasyMintBucket)
 	return resp, nil
 }
 
-func successfulMessageEmbed(resp *models.MintResp) []*discordgo.MessageEmbed{
-	embeds := []*discordgo.MessageEmbed{
-		&discordgo.MessageEmbed{
-			Type: discordgo.EmbedTypeRich,
-			Title: ":rainbow: Mint NFT successfully  :rainbow:",
-			Description: "Congratulate on minting NFT successfully! The NFT information is showed in the following.",
-			Image: &discordgo.MessageEmbedImage{
-				URL: "https://img0.baidu.com/it/u=2475308105,1312864556&fm=253&fmt=auto&app=138&f=JPEG?w=500&h=889",
-			},
-			Provider: &discordgo.MessageEmbedProvider{
-				Name: "come",
-				URL: "https://img0.baidu.com/it/u=2475308105,1312864556&fm=253&fmt=auto&app=138&f=JPEG?w=500&h=889",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				&discordgo.MessageEmbedField{
-					Name: "Mints Time",
-					Value: resp.Time,
-					Inline: true,
-				},
-				&discordgo.MessageEmbedField{
-					Name: "Contract",
-					Value: resp.Contract,
-					Inline: true,
-				},
-				&discordgo.MessageEmbedField{
-					Name: "Token ID",
-					Value: resp.TokenID,
-					Inline: true,
-				},
-				&discordgo.MessageEmbedField{
-					Name: "NFT URL",
-					Value: fmt.Sprintf("[VIEW IN CONFLUX SCAN](%s)", resp.NFTAddress),
-					Inline: false,
-				},
-				&discordgo.MessageEmbedField{
-					Name: "Advertise",
-					Value: viper.GetString("advertise"),
-					Inline: false,
-				},
-			},
-			Author: &discordgo.MessageEmbedAuthor{
-				Name: "NFTRainbow",
-				URL: "https://docs.nftrainbow.xyz/",
-				IconURL: "https://img0.baidu.com/it/u=2475308105,1312864556&fm=253&fmt=auto&app=138&f=JPEG?w=500&h=889",
-			},
-		},
+// customMintEdition returns the token id and amount each claimant gets of an ERC1155 campaign, and nil for ERC721
+// campaigns whose token ids are assigned by NFTRainbow.
+func customMintEdition() (*big.Int, *big.Int, error) {
+	if viper.GetString("customMint.contractType") != utils.ERC1155 {
+		return nil, nil, nil
 	}
-
-	return embeds
-}
-
-func failMessageEmbed(message string) []*discordgo.MessageEmbed{
-	embeds := []*discordgo.MessageEmbed{
-		&discordgo.MessageEmbed{
-			Type: discordgo.EmbedTypeRich,
-			Title: ":scream: Failed to Mint NFT  :scream:",
-			Description: "There is problem during minting NFT. ",
-			Image: &discordgo.MessageEmbedImage{
-				URL: "https://gimg2.baidu.com/image_search/src=http%3A%2F%2Ftva1.sinaimg.cn%2Fbmiddle%2F006APoFYly1g55m70z1uvj30hs0hidhd.jpg&refer=http%3A%2F%2Ftva1.sinaimg.cn&app=2002&size=f9999,10000&q=a80&n=0&g=0n&fmt=auto?sec=1664935347&t=223d106a8cbc9c825b5a34ff36b3678c",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				&discordgo.MessageEmbedField{
-					Name: "Error message",
-					Value: message,
-					Inline: false,
-				},
-				&discordgo.MessageEmbedField{
-					Name: "Advertise",
-					Value: viper.GetString("advertise"),
-					Inline: false,
-				},
-			},
-			Author: &discordgo.MessageEmbedAuthor{
-				Name: "NFTRainbow",
-				URL: "https://docs.nftrainbow.xyz/",
-				IconURL: "https://img0.baidu.com/it/u=2475308105,1312864556&fm=253&fmt=auto&app=138&f=JPEG?w=500&h=889",
-			},
-		},
+	edition, ok := new(big.Int).SetString(viper.GetString("customMint.tokenId"), 10)
+	if !ok || edition.Sign() < 0 {
+		return nil, nil, fmt.Errorf("invalid customMint.tokenId %q, erc1155 campaigns mint a fixed token id", viper.GetString("customMint.tokenId"))
 	}
-
-	return embeds
+	amount := big.NewInt(1)
+	if viper.IsSet("customMint.amount") && viper.GetString("customMint.amount") != "" {
+		if _, ok = amount.SetString(viper.GetString("customMint.amount"), 10); !ok || amount.Sign() <= 0 || !amount.IsUint64() {
+			return nil, nil, fmt.Errorf("invalid customMint.amount %q", viper.GetString("customMint.amount"))
+		}
+	}
+	return edition, amount, nil
 }
-
-
-
-
-
-
-
-
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/i18n"
+
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/viper"
+)
+
+// metadataAttribute is an attribute of the campaign metadata in the config.
+type metadataAttribute struct {
+	TraitType   string `mapstructure:"traitType"`
+	DisplayType string `mapstructure:"displayType"` // number, boost_number, boost_percentage or date
+	Value       string `mapstructure:"value"`
+}
+
+// claimant holds the placeholders of personalized metadata, they are rendered for each claim.
+type claimant struct {
+	UserID    string
+	Username  string
+	AvatarURL string
+	Guild     string
+	Campaign  string
+	ClaimedAt string // RFC 3339
+	Date      string // 2006-01-02
+	Timestamp int64  // unix seconds, for attributes displayed as date
+	Serial    uint64
+	MaxSupply uint64 // 0 when the campaign has no supply limit
+
+	Traits []*models.Trait // drawn from the rarity tables
+	Image  string          // the rendered badge, replaces the campaign image
+}
+
+// defaultPersonalAttributes are added to personalized metadata unless the campaign sets personalAttributes.
+var defaultPersonalAttributes = []metadataAttribute{
+	{TraitType: "Claimed By", Value: "{{.Username}}"},
+	{TraitType: "Guild", Value: "{{.Guild}}"},
+	{TraitType: "Claimed At", DisplayType: "date", Value: "{{.Timestamp}}"},
+	{TraitType: "Serial", Value: "#{{.Serial}}{{if .MaxSupply}} of {{.MaxSupply}}{{end}}"},
+	{TraitType: "Campaign", Value: "{{.Campaign}}"},
+}
+
+func newClaimant(s *discordgo.Session, i *discordgo.InteractionCreate, campaign string) *claimant {
+	user := interactionUser(i)
+	now := time.Now().UTC()
+	claim := &claimant{
+		UserID:    user.ID,
+		Username:  user.Username,
+		AvatarURL: user.AvatarURL("256"),
+		Campaign:  newEmbedData(i18n.DefaultLocale, campaign).Campaign,
+		ClaimedAt: now.Format(time.RFC3339),
+		Date:      now.Format("2006-01-02"),
+		Timestamp: now.Unix(),
+	}
+	if i.GuildID != "" {
+		if guild, err := s.State.Guild(i.GuildID); err == nil {
+			claim.Guild = guild.Name
+		} else if guild, err = s.Guild(i.GuildID); err == nil {
+			claim.Guild = guild.Name
+		}
+	}
+	return claim
+}
+
+// campaignMetadata reads the metadata of the campaign from the config.
+func campaignMetadata(campaign string) (models.Metadata, error) {
+	metadata := models.Metadata{
+		Name:            viper.GetString(campaign + ".name"),
+		Description:     viper.GetString(campaign + ".description"),
+		Image:           campaignFileURL(campaign),
+		ExternalLink:    viper.GetString(campaign + ".externalUrl"),
+		AnimationUrl:    viper.GetString(campaign + ".animationUrl"),
+		BackgroundColor: viper.GetString(campaign + ".backgroundColor"),
+	}
+	var attributes []metadataAttribute
+	if err := viper.UnmarshalKey(campaign+".attributes", &attributes); err != nil {
+		return metadata, err
+	}
+	for _, attribute := range attributes {
+		metadata.Attributes = append(metadata.Attributes, models.Attributes{
+			TraitType:   attribute.TraitType,
+			DisplayType: attribute.DisplayType,
+			Value:       attribute.Value,
+		})
+	}
+	return metadata, nil
+}
+
+// campaignMetadataURI returns the metadata uri of the campaign, creating the metadata on NFTRainbow only the first
+// time. The cache is keyed by a hash of the metadata content, so editing the campaign creates new metadata. Invalid
+// campaign metadata is an internal error, it has to be fixed in the config.
+//
+// Personalized campaigns, drawn traits and badges make the metadata unique to the claimant, so it is created on every claim.
+func campaignMetadataURI(token, campaign string, claim *claimant) (string, error) {
+	personalized := viper.GetBool(campaign+".personalized") && claim != nil
+	metadata, err := campaignMetadata(campaign)
+	if err == nil && personalized {
+		err = personalizeMetadata(&metadata, campaign, claim)
+	}
+	if claim != nil {
+		if claim.Image != "" {
+			metadata.Image = claim.Image
+		}
+		for _, trait := range claim.Traits {
+			metadata.Attributes = append(metadata.Attributes, models.Attributes{
+				TraitType: trait.TraitType,
+				Value:     trait.Value,
+			})
+		}
+	}
+	if err == nil {
+		err = service.ValidateMetadata(&metadata)
+	}
+	if err != nil {
+		return "", newClaimError(errInternal, err)
+	}
+
+	hash := ""
+	if !personalized && (claim == nil || len(claim.Traits) == 0 && claim.Image == "") {
+		hash, err = metadataHash(metadata)
+		if err != nil {
+			return "", newClaimError(errInternal, err)
+		}
+		if uri, err := database.GetMetadataURI(hash); err == nil && uri != "" {
+			return uri, nil
+		}
+	}
+
+	uri, err := service.CreateMetadata(token, metadata)
+	if err != nil {
+		return "", newClaimError(errUpstream, err)
+	}
+	if hash != "" {
+		if err = database.SetMetadataURI(hash, uri); err != nil {
+			log.Printf("Failed to cache the metadata uri of %s: %v", campaign, err)
+		}
+	}
+	return uri, nil
+}
+
+// personalizeMetadata renders the name, the description and the attributes of the metadata as text/templates with
+// the claimant, then adds the personal attributes. Attributes rendered empty, e.g. the guild of a DM claim, are left out.
+func personalizeMetadata(metadata *models.Metadata, campaign string, claim *claimant) error {
+	personal := defaultPersonalAttributes
+	if viper.IsSet(campaign + ".personalAttributes") {
+		if err := viper.UnmarshalKey(campaign+".personalAttributes", &personal); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	render := func(text string) string {
+		if err != nil || !strings.Contains(text, "{{") {
+			return text
+		}
+		var tmpl *template.Template
+		if tmpl, err = template.New("metadata").Parse(text); err != nil {
+			return text
+		}
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, claim)
+		return strings.TrimSpace(buf.String())
+	}
+
+	metadata.Name = render(metadata.Name)
+	metadata.Description = render(metadata.Description)
+	attributes := metadata.Attributes
+	for _, attribute := range personal {
+		attributes = append(attributes, models.Attributes{
+			TraitType:   attribute.TraitType,
+			DisplayType: attribute.DisplayType,
+			Value:       attribute.Value,
+		})
+	}
+	metadata.Attributes = nil
+	for _, attribute := range attributes {
+		attribute.TraitType = render(attribute.TraitType)
+		attribute.Value = render(attribute.Value)
+		if attribute.Value != "" {
+			metadata.Attributes = append(metadata.Attributes, attribute)
+		}
+	}
+	return err
+}
+
+// metadataHash identifies the metadata content, the NFTRainbow host included since uris are not shared between them.
+func metadataHash(metadata models.Metadata) (string, error) {
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(viper.GetString("host")), b...))
+	return hex.EncodeToString(sum[:]), nil
+}
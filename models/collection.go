@@ -0,0 +1,20 @@
+package models
+
+// CollectionManifest lists the items of a collection prepared by botCMD collection prepare.
+type CollectionManifest struct {
+	Name  string            `json:"name"`
+	Items []*CollectionItem `json:"items"`
+}
+
+// CollectionItem is a pre-made NFT of a collection. It is ready to be minted once MetadataUri is set.
+type CollectionItem struct {
+	ID           string   `json:"id"`
+	File         string   `json:"file"`
+	SHA256       string   `json:"sha256"`
+	FileUrl      string   `json:"file_url"`
+	Name         string   `json:"name"`
+	Traits       []*Trait `json:"traits,omitempty"`
+	MetadataHash string   `json:"metadata_hash"`
+	MetadataUri  string   `json:"metadata_uri"`
+	Error        string   `json:"error,omitempty"`
+}
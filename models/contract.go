@@ -1,7 +1,7 @@
 package models
 
 type ContractDeployDto struct {
-	Chain                     string `form:"chain" json:"chain" binding:"required,oneof=conflux conflux_test"`
+	Chain                     string `form:"chain" json:"chain" binding:"required,oneof=conflux conflux_test conflux_espace conflux_espace_test"`
 	Name                      string `form:"name" json:"name" binding:"required"`
 	Symbol                    string `form:"symbol" json:"symbol" binding:"required"`
 	OwnerAddress              string `form:"owner_address" json:"owner_address" binding:"required"`
@@ -29,3 +29,8 @@ type Contract struct {
 	TxId         uint   `gorm:"index" json:"tx_id"`
 	Status       uint   `json:"status"` // 0-pending, 1-success, 2-failed
 }
+
+type ContractList struct {
+	Count int         `json:"count"`
+	Items []*Contract `json:"items"`
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type EasyMintMetaDto struct {
-	Chain         string `form:"chain" json:"chain" binding:"required" oneof:"conflux conflux_test"`
+	Chain         string `form:"chain" json:"chain" binding:"required" oneof:"conflux conflux_test conflux_espace conflux_espace_test"`
 	Name          string `form:"name" json:"name" binding:"required"`
 	Description   string `form:"description" json:"description" binding:"required"`
 	FileUrl       string `form:"file_url" json:"file_url" binding:"required,uri"`
@@ -20,7 +20,7 @@ type CustomMintDto struct {
 }
 
 type ContractInfoDto struct {
-	Chain           string `form:"chain" json:"chain" binding:"required,oneof=conflux conflux_test"`
+	Chain           string `form:"chain" json:"chain" binding:"required,oneof=conflux conflux_test conflux_espace conflux_espace_test"`
 	ContractType    string `form:"contract_type" json:"contract_type" binding:"required,oneof=erc721 erc1155" `
 	ContractAddress string `form:"contract_address" json:"contract_address" binding:"required"`
 }
@@ -37,7 +37,33 @@ type MintResp struct {
 	NFTAddress string `form:"nft_address" json:"nft_address"`
 	Contract string `form:"advertise" json:"advertise"`
 	TokenID string `form:"token_id" json:"token_id"`
+	// Amount is the quantity of an ERC1155 edition, empty for ERC721 tokens
+	Amount string `json:"amount,omitempty"`
 	Time string `json:"created_at"`
+	Name string `json:"name"`
+	Image string `json:"image"`
+	Chain string `json:"chain"`
+	TxHash string `json:"tx_hash"`
+	TxURL string `json:"tx_url"`
+	// Final is set once the mint has been confirmed on chain
+	Final bool `json:"final"`
+	VerifyError string `json:"verify_error,omitempty"`
+	// Traits are drawn from the rarity tables of the campaign
+	Traits []*Trait `json:"traits,omitempty"`
+}
+
+// Trait is a drawn trait value and its chance in percent.
+type Trait struct {
+	TraitType string  `json:"trait_type"`
+	Value     string  `json:"value"`
+	Chance    float64 `json:"chance,omitempty"`
+	// Excluded are the values which had reached their cap when it was drawn
+	Excluded []string `json:"excluded,omitempty"`
+}
+
+type MintList struct {
+	Count int         `json:"count"`
+	Items []*MintTask `json:"items"`
 }
 
 
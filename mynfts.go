@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	nftsPerPage      = 5
+	mintListPageSize = 100
+	// nftPagesTTL is how long the page buttons of /mynfts serve the pages it collected
+	nftPagesTTL = 10 * time.Minute
+	// mintListTTL is how long the mint list of the app is reused
+	mintListTTL = 5 * time.Minute
+)
+
+type nftItem struct {
+	Chain    string
+	Contract string
+	TokenID  string
+	TokenURI string
+	Name     string
+	Image    string
+	Final    bool
+}
+
+type cachedNFTPages struct {
+	pages [][]*nftItem
+	at    time.Time
+}
+
+// nftPagesCache keeps the pages collected for an address, so paging does not fetch the whole mint list again.
+var (
+	nftPagesMu    sync.Mutex
+	nftPagesCache = make(map[string]*cachedNFTPages)
+)
+
+// remoteMints is the mint list of the app by address, see loadRemoteMints.
+var (
+	remoteMintsMu sync.Mutex
+	remoteMints   map[string][]*nftItem
+	remoteMintsAt time.Time
+)
+
+func handleMyNFTs(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	locale := i18n.InteractionLocale(i)
+	privacy := loadPrivacy("commands.mynfts")
+	address := ""
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		address = options[0].StringValue()
+	}
+	if address == "" {
+		address, _ = database.GetUserAddress(interactionUser(i).ID)
+	}
+	if address == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: i18n.T(locale, "mynfts.no_address"),
+				Flags:   uint64(discordgo.MessageFlagsEphemeral),
+			},
+		})
+		return
+	}
+
+	if privacy.DM {
+		// the details go to the DM channel, the interaction only gets a private acknowledgement
+		privacy.Ephemeral = true
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: privacy.flags(),
+		},
+	})
+
+	address, err := normalizeHolderAddress(address)
+	if err != nil {
+		s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Embeds: failMessageEmbed(locale, "", reportError(s, i, locale, "mynfts", err)),
+			Flags:  privacy.flags(),
+		})
+		return
+	}
+
+	pages, err := nftPages(address, true)
+	if err != nil {
+		s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Embeds: failMessageEmbed(locale, "", reportError(s, i, locale, "mynfts", err)),
+			Flags:  privacy.flags(),
+		})
+		return
+	}
+	if privacy.DM {
+		embeds, components := nftPageMessage(locale, address, address, pages, 0)
+		err = utils.SendDM(s, interactionUser(i).ID, &discordgo.MessageSend{
+			Embeds:     embeds,
+			Components: components,
+		})
+		if err == nil {
+			s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+				Content: i18n.T(locale, "privacy.dm_sent"),
+				Flags:   privacy.flags(),
+			})
+			return
+		}
+		log.Printf("Failed to send DM to %s: %v", interactionUser(i).ID, err)
+	}
+	embeds, components := nftPageMessage(locale, address, privacy.address(address), pages, 0)
+	s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds:     embeds,
+		Components: components,
+		Flags:      privacy.flags(),
+	})
+}
+
+// handleMyNFTsPage serves the previous/next buttons, whose custom id is "mynfts:<page>:<address>".
+// The address goes last since base32 addresses contain a colon themselves.
+func handleMyNFTsPage(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	locale := i18n.InteractionLocale(i)
+	parts := strings.SplitN(i.MessageComponentData().CustomID, ":", 3)
+	if len(parts) != 3 {
+		return
+	}
+	address := parts[2]
+	page, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	pages, err := nftPages(address, false)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: failMessageEmbed(locale, "", reportError(s, i, locale, "mynfts", err)),
+		})
+		return
+	}
+	// ephemeral and DM messages are not seen by others, so only public ones keep the address masked
+	shown := address
+	if i.Message != nil && i.Message.Flags&discordgo.MessageFlagsEphemeral == 0 && i.GuildID != "" {
+		shown = loadPrivacy("commands.mynfts").address(address)
+	}
+	embeds, components := nftPageMessage(locale, address, shown, pages, page)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     embeds,
+		Components: components,
+	})
+}
+
+// normalizeHolderAddress normalizes the address for the chain of the first campaign it is valid on, as the address
+// may have claimed on any of them.
+func normalizeHolderAddress(address string) (string, error) {
+	var firstErr error
+	for _, chain := range []string{campaignChain("customMint"), campaignChain("easyMint")} {
+		normalized, err := utils.NormalizeAddress(chain, address)
+		if err == nil {
+			return normalized, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
+
+// recordChain is the chain of a mint record kept before records had one: that of the campaign minting on its contract.
+func recordChain(contract string) string {
+	for campaign, key := range map[string]string{"customMint": "contractAddress", "easyMint": "contract"} {
+		if contract != "" && strings.EqualFold(contract, viper.GetString(campaign+"."+key)) {
+			return campaignChain(campaign)
+		}
+	}
+	return viper.GetString("chainType")
+}
+
+// nftPages returns the pages of the address, collected again when refresh is set or the cached ones expired.
+func nftPages(address string, refresh bool) ([][]*nftItem, error) {
+	nftPagesMu.Lock()
+	cached := nftPagesCache[address]
+	nftPagesMu.Unlock()
+	if !refresh && cached != nil && time.Since(cached.at) < nftPagesTTL {
+		return cached.pages, nil
+	}
+
+	pages, err := collectNFTPages(address)
+	if err != nil {
+		return nil, err
+	}
+	nftPagesMu.Lock()
+	defer nftPagesMu.Unlock()
+	for key, cached := range nftPagesCache {
+		if time.Since(cached.at) >= nftPagesTTL {
+			delete(nftPagesCache, key)
+		}
+	}
+	nftPagesCache[address] = &cachedNFTPages{pages: pages, at: time.Now()}
+	return pages, nil
+}
+
+// collectNFTPages merges the local mint records with the NFTRainbow mint list and splits them into pages, each page holding tokens of a single contract.
+func collectNFTPages(address string) ([][]*nftItem, error) {
+	records, err := database.GetMintRecords(address)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var items []*nftItem
+	for _, record := range records {
+		key := record.Contract + "/" + record.TokenID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		chain := record.Chain
+		if chain == "" {
+			chain = recordChain(record.Contract)
+		}
+		items = append(items, &nftItem{
+			Chain:    chain,
+			Contract: record.Contract,
+			TokenID:  record.TokenID,
+			Name:     record.Name,
+			Image:    record.Image,
+			Final:    record.Final,
+		})
+	}
+
+	remote, err := getRemoteNFTs(address)
+	if err != nil {
+		if len(items) == 0 {
+			return nil, err
+		}
+		log.Printf("Failed to get mint list from NFTRainbow, only local records are shown: %v", err)
+	}
+	for _, item := range remote {
+		key := item.Contract + "/" + item.TokenID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Contract != items[b].Contract {
+			return items[a].Contract < items[b].Contract
+		}
+		if len(items[a].TokenID) != len(items[b].TokenID) {
+			return len(items[a].TokenID) < len(items[b].TokenID)
+		}
+		return items[a].TokenID < items[b].TokenID
+	})
+
+	var pages [][]*nftItem
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && end-start < nftsPerPage && items[end].Contract == items[start].Contract {
+			end++
+		}
+		pages = append(pages, items[start:end])
+		start = end
+	}
+	return pages, nil
+}
+
+// getRemoteNFTs returns the tokens the app minted to the address, from the mint list of the app.
+func getRemoteNFTs(address string) ([]*nftItem, error) {
+	mints, err := loadRemoteMints()
+	if err != nil {
+		return nil, err
+	}
+	return mints[address], nil
+}
+
+// loadRemoteMints returns the minted tokens of the app by the address they were minted to. NFTRainbow does not filter
+// the mint list by address, so the whole list is fetched at most once per mintListTTL for every /mynfts. The lock is
+// held while it is fetched, concurrent commands wait for that fetch rather than starting their own.
+func loadRemoteMints() (map[string][]*nftItem, error) {
+	remoteMintsMu.Lock()
+	defer remoteMintsMu.Unlock()
+	if remoteMints != nil && time.Since(remoteMintsAt) < mintListTTL {
+		return remoteMints, nil
+	}
+
+	token, err := service.Login()
+	if err != nil {
+		return nil, err
+	}
+	mints := make(map[string][]*nftItem)
+	for page, fetched := 1, 0; ; page++ {
+		list, err := service.GetMintList(token, page, mintListPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range list.Items {
+			if task.Status != 1 || task.TokenId == "" {
+				continue
+			}
+			chain, err := utils.ChainNameByID(utils.ChainID(task.ChainId))
+			if err != nil {
+				continue
+			}
+			if mintTo, err := utils.NormalizeAddress(chain, task.MintTo); err == nil {
+				mints[mintTo] = append(mints[mintTo], &nftItem{
+					Chain:    chain,
+					Contract: task.Contract,
+					TokenID:  task.TokenId,
+					TokenURI: task.TokenURI,
+				})
+			}
+		}
+		fetched += len(list.Items)
+		if len(list.Items) == 0 || fetched >= list.Count {
+			break
+		}
+	}
+	remoteMints, remoteMintsAt = mints, time.Now()
+	return mints, nil
+}
+
+// nftPageMessage renders a page. The address is used in the button ids while shownAddress is the one displayed.
+func nftPageMessage(locale discordgo.Locale, address, shownAddress string, pages [][]*nftItem, page int) ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	if len(pages) == 0 {
+		return []*discordgo.MessageEmbed{
+			{
+				Type:        discordgo.EmbedTypeRich,
+				Title:       i18n.T(locale, "mynfts.title"),
+				Description: i18n.T(locale, "mynfts.empty", shownAddress),
+			},
+		}, nil
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= len(pages) {
+		page = len(pages) - 1
+	}
+
+	items := pages[page]
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       i18n.T(locale, "mynfts.title"),
+			Description: i18n.T(locale, "mynfts.header", shownAddress, items[0].Contract),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: i18n.T(locale, "mynfts.page", page+1, len(pages)),
+			},
+		},
+	}
+	for _, cached := range items {
+		// the items are shared by the cached pages, so the metadata is filled into a copy
+		item := *cached
+		if item.Name == "" && item.TokenURI != "" {
+			metadata, err := service.GetMetadata(item.TokenURI)
+			if err == nil {
+				item.Name = metadata.Name
+				item.Image = metadata.Image
+			}
+		}
+		url := utils.ExplorerByChain(item.Chain).NFT(item.Contract, item.TokenID)
+		embed := &discordgo.MessageEmbed{
+			Type:  discordgo.EmbedTypeRich,
+			Title: fmt.Sprintf("%s #%s", item.Name, item.TokenID),
+			URL:   url,
+		}
+		if url != "" {
+			embed.Description = fmt.Sprintf("[%s](%s)", i18n.T(locale, "embed.scan_link"), url)
+		}
+		if item.Final {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: i18n.T(locale, "mynfts.final")}
+		}
+		if item.Image != "" {
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: item.Image}
+		}
+		embeds = append(embeds, embed)
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    i18n.T(locale, "mynfts.previous"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("mynfts:%d:%s", page-1, address),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    i18n.T(locale, "mynfts.next"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("mynfts:%d:%s", page+1, address),
+					Disabled: page == len(pages)-1,
+				},
+			},
+		},
+	}
+	return embeds, components
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/spf13/viper"
+)
+
+// poolLoaded remembers the manifest each campaign pool was last loaded from.
+var (
+	poolLoadedMu sync.Mutex
+	poolLoaded   = make(map[string]string)
+)
+
+// poolEnabled tells whether the campaign hands out the pre-made NFTs of a manifest.
+func poolEnabled(campaign string) bool {
+	return viper.GetString(campaign+".pool.manifest") != ""
+}
+
+// loadCampaignPool adds the ready items of the manifest to the pool of the campaign. Items already in the pool are
+// kept as they are, so the manifest can grow while the campaign runs. A campaign closed because its pool ran out is
+// opened again when items are added.
+func loadCampaignPool(campaign string) error {
+	path := viper.GetString(campaign + ".pool.manifest")
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var manifest models.CollectionManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	var items []*models.CollectionItem
+	for _, item := range manifest.Items {
+		if item.MetadataUri != "" && item.Error == "" {
+			items = append(items, item)
+		}
+	}
+
+	added, err := database.AddPoolItems(campaign, poolManifest(campaign), items)
+	if err != nil {
+		return err
+	}
+	poolLoadedMu.Lock()
+	poolLoaded[campaign] = path
+	poolLoadedMu.Unlock()
+	if added > 0 {
+		log.Printf("Added %d items of %s to the pool of %s", added, path, campaign)
+		reopenPoolCampaign(campaign)
+	}
+	return nil
+}
+
+// poolManifest is the namespace of the items of the current manifest in the pool, so another collection whose item
+// ids overlap does not clash with the items loaded before.
+func poolManifest(campaign string) string {
+	return filepath.Clean(viper.GetString(campaign + ".pool.manifest"))
+}
+
+// reopenPoolCampaign opens the campaign again if it was closed because its pool ran out.
+func reopenPoolCampaign(campaign string) {
+	if closed, _ := database.GetCampaignSetting(campaign + ".closed"); closed == poolClosed {
+		_ = database.SetCampaignSetting(campaign+".closed", "")
+	}
+}
+
+// poolClosed marks a campaign closed because its pool ran out.
+const poolClosed = "pool"
+
+// takeCampaignPoolItem hands the next item of the pool out to the address, following pool.order (sequential or
+// random). The campaign is closed once the last item is minted, see poolItemMinted.
+func takeCampaignPoolItem(campaign, address string) (*models.CollectionItem, error) {
+	poolLoadedMu.Lock()
+	loaded := poolLoaded[campaign] == viper.GetString(campaign+".pool.manifest")
+	poolLoadedMu.Unlock()
+	if !loaded {
+		if err := loadCampaignPool(campaign); err != nil {
+			return nil, newClaimError(errInternal, fmt.Errorf("failed to load the pool of %s: %w", campaign, err))
+		}
+	}
+
+	item, _, err := database.TakePoolItem(campaign, poolManifest(campaign), address, viper.GetString(campaign+".pool.order") == "random")
+	if err != nil {
+		return nil, newClaimError(errInternal, err)
+	}
+	if item == nil {
+		return nil, &claimError{category: errSoldOut}
+	}
+	return item, nil
+}
+
+// poolItemMinted records the token the item of the address was minted as, and closes the campaign when that was the
+// last free item.
+func poolItemMinted(s *discordgo.Session, campaign, address, tokenID string) {
+	free, err := database.MarkPoolItemMinted(campaign, address, tokenID)
+	if err != nil {
+		log.Printf("Failed to mark the pool item of %s minted: %v", address, err)
+		return
+	}
+	if free == 0 {
+		closeCampaign(s, campaign, poolClosed, "The pool of pre-made NFTs is empty.")
+	}
+}
+
+// poolItemHeld tells whether the address holds an item it has not minted yet, such claims are retried even once the
+// campaign closed because its pool ran out.
+func poolItemHeld(campaign, address string) bool {
+	if closed, _ := database.GetCampaignSetting(campaign + ".closed"); closed != poolClosed {
+		return false
+	}
+	held, err := database.PoolItemHeld(campaign, address)
+	return err == nil && held
+}
+
+// releaseCampaignPoolItem puts the unminted item of the address back into the pool and reopens the campaign.
+func releaseCampaignPoolItem(campaign, address string) (bool, error) {
+	released, err := database.ReleasePoolItem(campaign, address)
+	if released {
+		log.Printf("Released the pool item of %s in %s", address, campaign)
+		reopenPoolCampaign(campaign)
+	}
+	return released, err
+}
+
+// closeCampaign stops the claims of the campaign until an admin opens it again, and tells the admins why.
+func closeCampaign(s *discordgo.Session, campaign, reason, message string) {
+	if closed, _ := database.GetCampaignSetting(campaign + ".closed"); closed != "" {
+		return
+	}
+	if err := database.SetCampaignSetting(campaign+".closed", reason); err != nil {
+		log.Printf("Failed to close %s: %v", campaign, err)
+		return
+	}
+	log.Printf("Closed %s: %s", campaign, message)
+	sendAdminLog(s, &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       "Campaign closed",
+		Description: message,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Campaign", Value: campaign, Inline: true},
+		},
+	})
+}
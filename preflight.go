@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+const contractListPageSize = 100
+
+// contractPreflight is the outcome of the last check of a campaign contract. It is only reused while the contract
+// settings stay the same.
+type contractPreflight struct {
+	settings string
+	err      error
+	retry    bool // NFTRainbow could not be reached
+}
+
+var (
+	preflightMu      sync.Mutex
+	preflightResults = make(map[string]*contractPreflight)
+)
+
+// preflightCustomMint checks the customMint contract before any user is marked Minting. The result is cached until
+// the contract settings change, except when NFTRainbow could not be reached, which is retried on the next claim.
+func preflightCustomMint(s *discordgo.Session) error {
+	const campaign = "customMint"
+	chain := campaignChain(campaign)
+	contract := viper.GetString(campaign + ".contractAddress")
+	contractType := viper.GetString(campaign + ".contractType")
+	settings := strings.Join([]string{chain, contract, contractType}, "|")
+
+	preflightMu.Lock()
+	last := preflightResults[campaign]
+	preflightMu.Unlock()
+	if last != nil && last.settings == settings && !last.retry {
+		return last.err
+	}
+
+	// the check calls NFTRainbow, so only its result is guarded and concurrent claims may check at the same time
+	err := checkCampaignContract(chain, contract, contractType)
+	preflightMu.Lock()
+	last = preflightResults[campaign]
+	preflightResults[campaign] = &contractPreflight{
+		settings: settings,
+		err:      err,
+		retry:    err != nil && classifyError(err).category == errUpstream,
+	}
+	preflightMu.Unlock()
+	if err == nil {
+		if last != nil && last.err != nil {
+			log.Printf("Contract preflight of %s passed", campaign)
+		}
+		return nil
+	}
+
+	log.Printf("Contract preflight of %s failed: %v", campaign, err)
+	// only alert once per settings and failure
+	if last == nil || last.settings != settings || last.err == nil || last.err.Error() != err.Error() {
+		sendAdminLog(s, &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       "Campaign contract preflight failed",
+			Description: "Claims of the campaign are refused until the contract settings are fixed.",
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Campaign", Value: campaign, Inline: true},
+				{Name: "Chain", Value: orDash(chain), Inline: true},
+				{Name: "Type", Value: orDash(contractType), Inline: true},
+				{Name: "Contract", Value: orDash(contract)},
+				{Name: "Error", Value: truncate(err.Error(), 1024)},
+			},
+		})
+	}
+	return err
+}
+
+// checkCampaignContract makes sure the contract is deployed by our app on the chain, with the declared type.
+func checkCampaignContract(chain, contract, contractType string) error {
+	unavailable := func(format string, args ...interface{}) error {
+		return &claimError{category: errCampaignUnavailable, err: fmt.Errorf(format, args...)}
+	}
+
+	wantType, err := utils.ContractTypeByName(contractType)
+	if err != nil {
+		return unavailable("invalid contractType: %v", err)
+	}
+	_, chainId, err := utils.ChainInfoByName(chain)
+	if err != nil {
+		return unavailable("invalid chainType: %v", err)
+	}
+	address, err := utils.NormalizeAddress(chain, contract)
+	if err != nil {
+		return unavailable("invalid contractAddress %q: %v", contract, err)
+	}
+
+	token, err := service.Login()
+	if err != nil {
+		return newClaimError(errUpstream, err)
+	}
+	var id uint
+	for page, fetched := 1, 0; id == 0; page++ {
+		list, err := service.GetContractList(token, page, contractListPageSize)
+		if err != nil {
+			return newClaimError(errUpstream, err)
+		}
+		for _, c := range list.Items {
+			if utils.ChainID(c.ChainId) != chainId {
+				continue
+			}
+			if normalized, err := utils.NormalizeAddress(chain, c.Address); err == nil && normalized == address {
+				id = c.ID
+				break
+			}
+		}
+		fetched += len(list.Items)
+		if len(list.Items) == 0 || fetched >= list.Count {
+			break
+		}
+	}
+	if id == 0 {
+		return unavailable("contract %s is not deployed by this app on %s", address, chain)
+	}
+
+	detail, err := service.GetContractDetail(token, id)
+	if err != nil {
+		return newClaimError(errUpstream, err)
+	}
+	if detail.Status != 1 {
+		return unavailable("contract %s is not deployed yet (status %d)", address, detail.Status)
+	}
+	if utils.ContractType(detail.Type) != wantType {
+		return unavailable("contract %s is not an %s contract (type %d)", address, contractType, detail.Type)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// privacySettings controls who can see the responses of a command. Each setting is read from "<scope>.privacy"
+// of the first scope which sets it, then from the global "privacy".
+type privacySettings struct {
+	Ephemeral   bool
+	MaskAddress bool
+	DM          bool
+}
+
+func loadPrivacy(scopes ...string) privacySettings {
+	return privacySettings{
+		Ephemeral:   privacyBool("ephemeral", scopes),
+		MaskAddress: privacyBool("maskAddress", scopes),
+		DM:          privacyBool("dm", scopes),
+	}
+}
+
+func privacyBool(name string, scopes []string) bool {
+	for _, scope := range scopes {
+		if key := scope + ".privacy." + name; viper.IsSet(key) {
+			return viper.GetBool(key)
+		}
+	}
+	return viper.GetBool("privacy." + name)
+}
+
+func (p privacySettings) flags() uint64 {
+	if p.Ephemeral {
+		return uint64(discordgo.MessageFlagsEphemeral)
+	}
+	return 0
+}
+
+// address masks the address unless the message is only visible to the user.
+func (p privacySettings) address(address string) string {
+	if p.MaskAddress && !p.Ephemeral {
+		return utils.MaskAddress(address)
+	}
+	return address
+}
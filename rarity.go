@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/spf13/viper"
+)
+
+// rarityTable is the pool a trait is drawn from, e.g. background: gold 2, silver 18, bronze 80.
+type rarityTable struct {
+	TraitType string        `mapstructure:"traitType"`
+	Values    []rarityValue `mapstructure:"values"`
+}
+
+type rarityValue struct {
+	Value  string  `mapstructure:"value"`
+	Weight float64 `mapstructure:"weight"`
+	Cap    uint64  `mapstructure:"cap"` // 0 for no cap
+}
+
+// drawCampaignTraits draws a value of each rarity table of the campaign for the address. The draw is stored: a claim
+// which fails before its mint is requested gives it back and its retry draws again, while a claimant retrying after
+// the mint was requested, which may have landed, gets the same traits.
+//
+// A draw is replayed by seeding math/rand with the first 8 bytes (big endian) of the stored seed and, for each table
+// in order, picking the value at rand.Float64() times the total weight, leaving out the values which were capped.
+func drawCampaignTraits(campaign, address string) ([]*models.Trait, error) {
+	var tables []rarityTable
+	if err := viper.UnmarshalKey(campaign+".traits", &tables); err != nil {
+		return nil, newClaimError(errInternal, fmt.Errorf("invalid %s.traits: %w", campaign, err))
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	record, err := database.DrawRarity(campaign, address, func(seed []byte, count func(traitType, value string) uint64) ([]*models.Trait, error) {
+		rng := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed[:8]))))
+		var traits []*models.Trait
+		for _, table := range tables {
+			trait, err := drawTrait(rng, table, count)
+			if err != nil {
+				return nil, err
+			}
+			traits = append(traits, trait)
+		}
+		return traits, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record.Traits, nil
+}
+
+func drawTrait(rng *rand.Rand, table rarityTable, count func(traitType, value string) uint64) (*models.Trait, error) {
+	trait := &models.Trait{TraitType: table.TraitType}
+	var total, eligible float64
+	var candidates []rarityValue
+	for _, value := range table.Values {
+		if value.Weight <= 0 {
+			return nil, newClaimError(errInternal, fmt.Errorf("trait %s value %s has no weight", table.TraitType, value.Value))
+		}
+		total += value.Weight
+		if value.Cap > 0 && count(table.TraitType, value.Value) >= value.Cap {
+			trait.Excluded = append(trait.Excluded, value.Value)
+			continue
+		}
+		eligible += value.Weight
+		candidates = append(candidates, value)
+	}
+	if len(candidates) == 0 {
+		// every value reached its cap, the campaign cannot give out another combination
+		return nil, &claimError{category: errSoldOut, err: fmt.Errorf("all values of trait %s reached their cap", table.TraitType)}
+	}
+
+	pick := rng.Float64() * eligible
+	chosen := candidates[len(candidates)-1]
+	for _, value := range candidates {
+		if pick < value.Weight {
+			chosen = value
+			break
+		}
+		pick -= value.Weight
+	}
+	trait.Value = chosen.Value
+	trait.Chance = chosen.Weight / total * 100
+	return trait, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/reveal"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/spf13/viper"
+)
+
+// revealPending tells whether the campaign mints placeholder metadata, which is until it is revealed.
+func revealPending(campaign string) bool {
+	if !viper.GetBool(campaign + ".reveal.enabled") {
+		return false
+	}
+	revealed, _ := database.GetCampaignSetting(campaign + ".revealed")
+	return revealed == ""
+}
+
+// campaignPlaceholder creates the placeholder metadata of a token from reveal.name, reveal.description and
+// reveal.image. Every token gets its own placeholder, as the reveal updates it to the final metadata of the token.
+func campaignPlaceholder(token, campaign string) (*models.CreateMetadataResponse, *models.Metadata, error) {
+	metadata := &models.Metadata{
+		Name:        viper.GetString(campaign + ".reveal.name"),
+		Description: viper.GetString(campaign + ".reveal.description"),
+		Image:       viper.GetString(campaign + ".reveal.image"),
+	}
+	if metadata.Name == "" {
+		metadata.Name = viper.GetString(campaign + ".name")
+	}
+	if metadata.Description == "" {
+		metadata.Description = viper.GetString(campaign + ".description")
+	}
+	if err := service.ValidateMetadata(metadata); err != nil {
+		return nil, nil, newClaimError(errInternal, fmt.Errorf("invalid %s.reveal placeholder: %w", campaign, err))
+	}
+	res, err := service.CreateMetadataInfo(token, *metadata)
+	if err != nil {
+		return nil, nil, newClaimError(errUpstream, err)
+	}
+	if res.MetadataID == "" || res.MetadataURI == "" {
+		return nil, nil, newClaimError(errUpstream, fmt.Errorf("NFTRainbow returned no id or uri for the placeholder of %s", campaign))
+	}
+	return res, metadata, nil
+}
+
+// recordRevealToken fills in the token the placeholder of the entry was minted as. The reveal looks the token up by
+// its placeholder uri when this fails, so a failure is only logged.
+func recordRevealToken(campaign string, entry *database.RevealEntry, tokenID string) {
+	if err := database.SetRevealToken(campaign, entry.PlaceholderID, entry.Contract, tokenID, time.Now()); err != nil {
+		log.Printf("Failed to record token %s of the placeholder %s: %v", tokenID, entry.PlaceholderID, err)
+	}
+}
+
+func handleAdminReveal(s *discordgo.Session, i *discordgo.InteractionCreate, options map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	locale := i18n.InteractionLocale(i)
+	campaign := options["campaign"].StringValue()
+	if !viper.GetBool(campaign + ".reveal.enabled") {
+		adminFollowup(s, i, i18n.T(locale, "admin.reveal.disabled", campaign))
+		return
+	}
+
+	result, err := revealCampaign(s, campaign)
+	if err != nil {
+		adminFollowup(s, i, reportError(s, i, locale, campaign, err))
+		return
+	}
+	log.Printf("%s revealed %s: %d revealed, %d failed, %d pending", interactionUser(i).ID, campaign, len(result.Revealed), len(result.Failed), result.Pending)
+	if len(result.Revealed) == 0 && len(result.Failed) == 0 && result.Pending == 0 {
+		adminFollowup(s, i, i18n.T(locale, "admin.reveal.nothing", campaign))
+		return
+	}
+	content := i18n.T(locale, "admin.reveal.done", len(result.Revealed), campaign, len(result.Failed))
+	if result.Pending > 0 {
+		content += "\n" + i18n.T(locale, "admin.reveal.pending", result.Pending)
+	}
+	adminFollowup(s, i, content)
+}
+
+// revealCampaign marks the campaign revealed, so new claims mint the final metadata, then reveals the tokens minted
+// with a placeholder and announces them in reveal.channelId and to their holders. The level job waits for it, and
+// levels the revealed tokens again from their final metadata.
+func revealCampaign(s *discordgo.Session, campaign string) (*reveal.Result, error) {
+	dynamicMu.Lock()
+	defer dynamicMu.Unlock()
+	if revealed, _ := database.GetCampaignSetting(campaign + ".revealed"); revealed == "" {
+		if err := database.SetCampaignSetting(campaign+".revealed", time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return nil, newClaimError(errInternal, err)
+		}
+	}
+	token, err := service.Login()
+	if err != nil {
+		return nil, newClaimError(errUpstream, err)
+	}
+	result, err := reveal.Run(token, campaign)
+	if err != nil {
+		return nil, newClaimError(errInternal, err)
+	}
+
+	reveal.Announce(s, viper.GetString(campaign+".reveal.channelId"), newEmbedData(i18n.Pick(), campaign).Campaign, result.Revealed)
+	if len(result.Failed) > 0 {
+		embed := &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       "Reveal incomplete",
+			Description: fmt.Sprintf("%d tokens could not be revealed, reveal the campaign again to retry them.", len(result.Failed)),
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Campaign", Value: campaign, Inline: true},
+			},
+		}
+		for _, entry := range result.Failed {
+			// an embed holds at most 25 fields
+			if len(embed.Fields) == 25 {
+				break
+			}
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Token " + entry.TokenID, Value: entry.Error})
+		}
+		sendAdminLog(s, embed)
+	}
+	return result, nil
+}
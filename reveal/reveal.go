@@ -0,0 +1,220 @@
+// Package reveal turns the placeholder metadata of a campaign minted before its reveal into the final metadata. It
+// is shared by the /admin reveal command of the bot and botCMD reveal.
+package reveal
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/service"
+	"github.com/nft-rainbow/discordBot/utils"
+)
+
+// Revealed is a token revealed by a run, with the metadata it shows now.
+type Revealed struct {
+	Entry    *database.RevealEntry
+	Metadata *models.Metadata
+}
+
+type Result struct {
+	Revealed []*Revealed
+	Failed   []*database.RevealEntry
+	// Pending counts the claims which were still minting, they are revealed by the next run
+	Pending int
+	// Skipped counts the tokens revealed by an earlier run
+	Skipped int
+}
+
+// mintGracePeriod is how long a claim whose mint task is not found yet is taken for one still being requested.
+const mintGracePeriod = time.Hour
+
+// Run reveals every token of the campaign which is not revealed yet: the final metadata is read from its uri and
+// written over the placeholder of the token. Each token is recorded as soon as it is done, so a run which failed
+// halfway, or tokens claimed while it ran, are picked up by running it again.
+//
+// Entries are recorded before their mint is requested. Those whose token is not known yet, because the claim is still
+// minting or failed after the mint was requested, are looked up in the mint tasks of the app by their placeholder uri:
+// minted ones are revealed, failed ones are forgotten and the others are left pending.
+func Run(token, campaign string) (*Result, error) {
+	entries, err := database.GetRevealEntries(campaign)
+	if err != nil {
+		return nil, err
+	}
+	if entries, err = resolveMints(token, campaign, entries); err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	for _, entry := range entries {
+		if entry.RevealedAt != nil {
+			result.Skipped++
+			continue
+		}
+		if entry.TokenID == "" {
+			result.Pending++
+			continue
+		}
+		metadata, err := revealToken(token, entry)
+		if err != nil {
+			log.Printf("Failed to reveal token %s of %s: %v", entry.TokenID, entry.Contract, err)
+			entry.Error = err.Error()
+			result.Failed = append(result.Failed, entry)
+		} else {
+			now := time.Now()
+			entry.RevealedAt = &now
+			entry.Error = ""
+			result.Revealed = append(result.Revealed, &Revealed{Entry: entry, Metadata: metadata})
+			// the final metadata replaced any level applied to the placeholder, it is applied again
+			if err = database.ResetDynamicLevel(campaign, entry.Contract, entry.TokenID); err != nil {
+				log.Printf("Failed to reset the level of token %s of %s: %v", entry.TokenID, entry.Contract, err)
+			}
+		}
+		if err = database.PutRevealEntry(campaign, entry); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// resolveMints fills in the tokens of the entries whose claim did not record them, from the mint tasks minting their
+// placeholder uri. Entries whose mint task failed, or which have none long after they were requested, are deleted and
+// left out of the entries returned.
+func resolveMints(token, campaign string, entries []*database.RevealEntry) ([]*database.RevealEntry, error) {
+	unknown := make(map[string]*database.RevealEntry)
+	for _, entry := range entries {
+		if entry.RevealedAt == nil && entry.TokenID == "" {
+			unknown[entry.PlaceholderURI] = entry
+		}
+	}
+	if len(unknown) == 0 {
+		return entries, nil
+	}
+
+	tasks := make(map[string]*models.MintTask)
+	for page, fetched := 1, 0; len(tasks) < len(unknown); page++ {
+		list, err := service.GetMintList(token, page, 100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the mints of the pending claims: %w", err)
+		}
+		for _, task := range list.Items {
+			if _, ok := unknown[task.TokenURI]; ok {
+				tasks[task.TokenURI] = task
+			}
+		}
+		fetched += len(list.Items)
+		if len(list.Items) == 0 || fetched >= list.Count {
+			break
+		}
+	}
+
+	var kept []*database.RevealEntry
+	for _, entry := range entries {
+		if unknown[entry.PlaceholderURI] != entry {
+			kept = append(kept, entry)
+			continue
+		}
+		task := tasks[entry.PlaceholderURI]
+		switch {
+		case task != nil && task.Status == 1 && task.TokenId != "":
+			if err := database.SetRevealToken(campaign, entry.PlaceholderID, task.Contract, task.TokenId, task.UpdatedAt); err != nil {
+				return nil, err
+			}
+			entry.Contract, entry.TokenID, entry.MintedAt = task.Contract, task.TokenId, task.UpdatedAt
+		case task != nil && task.Status == 2, task == nil && time.Since(entry.RequestedAt) > mintGracePeriod:
+			log.Printf("Forgetting the placeholder %s of %s, it was never minted", entry.PlaceholderID, entry.Address)
+			if err := database.DeleteRevealEntry(campaign, entry.PlaceholderID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, nil
+}
+
+func revealToken(token string, entry *database.RevealEntry) (*models.Metadata, error) {
+	if entry.PlaceholderID == "" || entry.FinalURI == "" {
+		return nil, fmt.Errorf("the placeholder or final metadata of the token is unknown")
+	}
+	metadata, err := service.GetMetadata(entry.FinalURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the final metadata %s: %w", entry.FinalURI, err)
+	}
+	// the content is copied, the placeholder keeps its own id and uri
+	metadata.ID, metadata.URI = "", ""
+	if err = service.UpdateMetadata(token, entry.PlaceholderID, *metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// Announce posts the reveal to the channel, when one is given, and tells each holder by DM which of their tokens were
+// revealed. Discord errors are logged, a holder who does not accept DMs must not stop the others.
+func Announce(s *discordgo.Session, channelID, campaign string, revealed []*Revealed) {
+	if len(revealed) == 0 {
+		return
+	}
+	locale := i18n.Pick()
+	if channelID != "" {
+		_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content: i18n.T(locale, "reveal.announcement", campaign, len(revealed)),
+			Embeds:  tokenEmbeds(locale, revealed[:1]),
+		})
+		if err != nil {
+			log.Printf("Failed to announce the reveal of %s: %v", campaign, err)
+		}
+	}
+
+	holders := make(map[string][]*Revealed)
+	var order []string
+	for _, r := range revealed {
+		if r.Entry.UserID == "" {
+			continue
+		}
+		if _, ok := holders[r.Entry.UserID]; !ok {
+			order = append(order, r.Entry.UserID)
+		}
+		holders[r.Entry.UserID] = append(holders[r.Entry.UserID], r)
+	}
+	for _, userID := range order {
+		tokens := holders[userID]
+		// a message holds at most 10 embeds
+		for start := 0; start < len(tokens); start += 10 {
+			end := start + 10
+			if end > len(tokens) {
+				end = len(tokens)
+			}
+			if err := utils.SendDM(s, userID, &discordgo.MessageSend{
+				Content: i18n.T(locale, "reveal.dm", campaign),
+				Embeds:  tokenEmbeds(locale, tokens[start:end]),
+			}); err != nil {
+				log.Printf("Failed to DM %s about the reveal of %s: %v", userID, campaign, err)
+				break
+			}
+		}
+	}
+}
+
+func tokenEmbeds(locale discordgo.Locale, revealed []*Revealed) []*discordgo.MessageEmbed {
+	var embeds []*discordgo.MessageEmbed
+	for _, r := range revealed {
+		embed := &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       r.Metadata.Name,
+			Description: r.Metadata.Description,
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: i18n.T(locale, "embed.field.contract"), Value: r.Entry.Contract, Inline: true},
+				{Name: i18n.T(locale, "embed.field.token_id"), Value: r.Entry.TokenID, Inline: true},
+			},
+		}
+		if r.Metadata.Image != "" {
+			embed.Image = &discordgo.MessageEmbedImage{URL: r.Metadata.Image}
+		}
+		embeds = append(embeds, embed)
+	}
+	return embeds
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/nft-rainbow/discordBot/models"
-	"github.com/nft-rainbow/discordBot/utils"
 	"github.com/spf13/viper"
 	"io/ioutil"
 	"net/http"
@@ -14,9 +13,9 @@ import (
 	"time"
 )
 
-func DeployContract(token, name, symbol, owner, contractType string) (string, error){
+func DeployContract(token, chain, name, symbol, owner, contractType string) (string, error){
 	contract := models.ContractDeployDto{
-		Chain: utils.CONFLUX_TEST,
+		Chain: chain,
 		Name: name,
 		Symbol: symbol,
 		OwnerAddress: owner,
@@ -91,3 +90,51 @@ func getContractAddress(id uint, token string) (string, error){
 	}
 	return t.Address, nil
 }
+
+// GetContractList returns one page of the contracts deployed by the app.
+func GetContractList(token string, page, size int) (*models.ContractList, error) {
+	var list models.ContractList
+	err := getContractAPI(token, fmt.Sprintf("v1/contracts/?page=%d&size=%d", page, size), &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetContractDetail returns the contract of the app with the id.
+func GetContractDetail(token string, id uint) (*models.Contract, error) {
+	var contract models.Contract
+	err := getContractAPI(token, "v1/contracts/detail/" + strconv.Itoa(int(id)), &contract)
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+func getContractAPI(token, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", viper.GetString("host") + path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer " + token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	t := make(map[string]interface{})
+	err = json.Unmarshal(content, &t)
+	if err != nil {
+		return err
+	}
+	if t["code"] != nil {
+		return fmt.Errorf("%v", t["message"])
+	}
+	return json.Unmarshal(content, v)
+}
@@ -1,51 +1,216 @@
 package service
 
 import (
-	"bytes"
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/nft-rainbow/discordBot/models"
 	"github.com/spf13/viper"
 	"io"
 	"io/ioutil"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultMaxUploadSize = 20 << 20
+
+var defaultUploadTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml", "video/mp4", "audio/mpeg"}
+
+var (
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("unsupported file type")
 )
 
 func UploadFile(token, path string) (string, error){
-	file, err := os.Open(path)
+	res, err := UploadFileInfo(token, path)
 	if err != nil {
 		return "", err
 	}
+	return res.FileUrl, nil
+}
+
+// UploadFileInfo uploads the file like UploadFile, returning the size and type NFTRainbow recorded along with the url.
+// Local files are uploaded by the operator, e.g. with botCMD, so the upload limits do not apply to them.
+func UploadFileInfo(token, path string) (*models.UploadFilesResponse, error){
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
-	bodyBuffer := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuffer)
 
-	fileWriter, _ := bodyWriter.CreateFormFile("file", file.Name())
+	return upload(token, filepath.Base(path), mime.TypeByExtension(filepath.Ext(path)), file, false)
+}
+
+// downloadClient downloads the files forwarded by UploadURL, its timeout covers the whole forward as the body is
+// streamed to NFTRainbow.
+var downloadClient = &http.Client{Timeout: time.Minute}
 
-	io.Copy(fileWriter, file)
+// UploadURL downloads the file at the url, e.g. a Discord attachment, and forwards it to NFTRainbow without keeping it.
+func UploadURL(token, fileUrl string) (string, error){
+	resp, err := downloadClient.Get(fileUrl)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", fileUrl, resp.Status)
+	}
+	if resp.ContentLength > MaxUploadSize() {
+		return "", fmt.Errorf("%w: %d bytes, at most %d", ErrFileTooLarge, resp.ContentLength, MaxUploadSize())
+	}
+
+	filename := "file"
+	if u, err := url.Parse(fileUrl); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		filename = path.Base(u.Path)
+	}
+	return UploadReader(token, filename, resp.Header.Get("Content-Type"), resp.Body)
+}
+
+// UploadReader streams the content to NFTRainbow as a multipart upload and returns the file url. The content type is
+// sniffed from the content when it is not given. Files over upload.maxSize bytes or whose type is not in
+// upload.allowedTypes are refused.
+func UploadReader(token, filename, contentType string, r io.Reader) (string, error){
+	res, err := upload(token, filename, contentType, r, true)
+	if err != nil {
+		return "", err
+	}
+	return res.FileUrl, nil
+}
 
-	contentType := bodyWriter.FormDataContentType()
-	bodyWriter.Close()
+// upload streams the content to NFTRainbow, refusing it when it is over the upload limits and limited is set.
+func upload(token, filename, contentType string, r io.Reader, limited bool) (*models.UploadFilesResponse, error){
+	body := bufio.NewReaderSize(r, 512)
+	// Peek returns what it could read with an error, which surfaces again when the content is copied
+	head, _ := body.Peek(512)
+	sniffed := http.DetectContentType(head)
+	if contentType == "" {
+		contentType = sniffed
+	}
+	if limited {
+		if err := checkUploadType(contentType); err != nil {
+			return nil, err
+		}
+		if err := checkUploadContent(contentType, sniffed); err != nil {
+			return nil, err
+		}
+	}
 
-	req, _ := http.NewRequest("POST", viper.GetString("host") + "v1/files", bodyBuffer)
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+	go func() {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(filename)))
+		header.Set("Content-Type", contentType)
+		fileWriter, err := bodyWriter.CreatePart(header)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		var n int64
+		if limited {
+			max := MaxUploadSize()
+			n, err = io.Copy(fileWriter, io.LimitReader(body, max+1))
+			if err == nil && n > max {
+				err = fmt.Errorf("%w: more than %d bytes", ErrFileTooLarge, max)
+			}
+		} else {
+			_, err = io.Copy(fileWriter, body)
+		}
+		if err == nil {
+			err = bodyWriter.Close()
+		}
+		pw.CloseWithError(err)
+	}()
 
+	req, err := http.NewRequest("POST", viper.GetString("host") + "v1/files", pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
 	req.Header.Add("Authorization", "Bearer " + token)
-	req.Header.Add("Content-Type", contentType)
-	res, _ := http.DefaultClient.Do(req)
+	req.Header.Add("Content-Type", bodyWriter.FormDataContentType())
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// unwraps the error of the pipe, such as ErrFileTooLarge
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && (errors.Is(urlErr.Err, ErrFileTooLarge) || errors.Is(urlErr.Err, ErrUnsupportedFileType)) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	pr.Close()
 
 	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
+	content, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	t := make(map[string]interface{})
+	err = json.Unmarshal(content, &t)
+	if err != nil {
+		return nil, err
+	}
+	if t["code"] != nil {
+		return nil, fmt.Errorf("%v", t["message"])
 	}
 
-	var t models.UploadFilesResponse
-
-	err = json.Unmarshal(body, &t)
+	var tmp models.UploadFilesResponse
+	err = json.Unmarshal(content, &tmp)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return &tmp, nil
+}
 
-	return t.FileUrl, nil
+// MaxUploadSize is the size limit of uploads in bytes, upload.maxSize or 20 MB.
+func MaxUploadSize() int64 {
+	if size := viper.GetInt64("upload.maxSize"); size > 0 {
+		return size
+	}
+	return defaultMaxUploadSize
+}
+
+// checkUploadContent makes sure the content looks like the type it claims to be. Only text types and xml ones such as
+// svg, which the sniffer takes for text, may be text, and only html may be html. Content unknown to the sniffer passes.
+func checkUploadContent(contentType, sniffed string) error {
+	if sniffed == "application/octet-stream" {
+		return nil
+	}
+	if !strings.HasPrefix(sniffed, "text/") {
+		return checkUploadType(sniffed)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+	sniffedType, _, _ := mime.ParseMediaType(sniffed)
+	text := strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") || mediaType == "application/xml"
+	if !text || (sniffedType == "text/html" && mediaType != "text/html") {
+		return fmt.Errorf("%w: %s content declared as %s", ErrUnsupportedFileType, sniffedType, mediaType)
+	}
+	return nil
+}
+
+func checkUploadType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFileType, contentType)
+	}
+	allowed := viper.GetStringSlice("upload.allowedTypes")
+	if len(allowed) == 0 {
+		allowed = defaultUploadTypes
+	}
+	for _, t := range allowed {
+		if strings.EqualFold(t, mediaType) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedFileType, mediaType)
 }
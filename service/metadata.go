@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nft-rainbow/discordBot/models"
+)
+
+var backgroundColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// ValidateMetadata checks the metadata against the OpenSea metadata standard which marketplaces and scans expect. A
+// background colour given as "#rrggbb" is normalized to the six hex digits of the standard.
+func ValidateMetadata(metadata *models.Metadata) error {
+	if metadata.Name == "" {
+		return errors.New("metadata name is required")
+	}
+	if metadata.Description == "" {
+		return errors.New("metadata description is required")
+	}
+	if metadata.Image == "" {
+		return errors.New("metadata image is required")
+	}
+	for field, uri := range map[string]string{
+		"image":         metadata.Image,
+		"external_link": metadata.ExternalLink,
+		"animation_url": metadata.AnimationUrl,
+	} {
+		if uri != "" && !validMetadataURI(uri) {
+			return fmt.Errorf("metadata %s %q is not an http(s), ipfs or ar uri", field, uri)
+		}
+	}
+
+	metadata.BackgroundColor = strings.TrimPrefix(metadata.BackgroundColor, "#")
+	if metadata.BackgroundColor != "" && !backgroundColorPattern.MatchString(metadata.BackgroundColor) {
+		return fmt.Errorf("metadata background_color %q must be six hex digits", metadata.BackgroundColor)
+	}
+
+	for _, attribute := range metadata.Attributes {
+		if attribute.Value == "" {
+			return fmt.Errorf("metadata attribute %q has no value", attribute.TraitType)
+		}
+		switch attribute.DisplayType {
+		case "":
+		case "number", "boost_number", "boost_percentage":
+			if _, err := strconv.ParseFloat(attribute.Value, 64); err != nil {
+				return fmt.Errorf("metadata attribute %q is displayed as %s but %q is not a number", attribute.TraitType, attribute.DisplayType, attribute.Value)
+			}
+		case "date":
+			if _, err := strconv.ParseInt(attribute.Value, 10, 64); err != nil {
+				return fmt.Errorf("metadata attribute %q is a date but %q is not a unix timestamp", attribute.TraitType, attribute.Value)
+			}
+		default:
+			return fmt.Errorf("metadata attribute %q has the unknown display_type %q", attribute.TraitType, attribute.DisplayType)
+		}
+		if attribute.DisplayType != "" && attribute.TraitType == "" {
+			return fmt.Errorf("metadata attribute %q needs a trait_type to be displayed as %s", attribute.Value, attribute.DisplayType)
+		}
+	}
+	return nil
+}
+
+func validMetadataURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "ipfs", "ar":
+		return u.Host != "" || u.Opaque != "" || u.Path != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nft-rainbow/discordBot/models"
+)
+
+func validTestMetadata() *models.Metadata {
+	return &models.Metadata{
+		Name:        "Rainbow #1",
+		Description: "The first rainbow",
+		Image:       "https://nftrainbow.oss-cn-hangzhou.aliyuncs.com/1.png",
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*models.Metadata)
+		err    string
+	}{
+		{"valid", func(*models.Metadata) {}, ""},
+		{"no name", func(m *models.Metadata) { m.Name = "" }, "metadata name is required"},
+		{"no description", func(m *models.Metadata) { m.Description = "" }, "metadata description is required"},
+		{"no image", func(m *models.Metadata) { m.Image = "" }, "metadata image is required"},
+		{"ipfs image", func(m *models.Metadata) { m.Image = "ipfs://QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG/1.png" }, ""},
+		{"ar image", func(m *models.Metadata) { m.Image = "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U" }, ""},
+		{"image without scheme", func(m *models.Metadata) { m.Image = "1.png" }, `metadata image "1.png"`},
+		{"image without host", func(m *models.Metadata) { m.Image = "https:///1.png" }, "metadata image"},
+		{"file image", func(m *models.Metadata) { m.Image = "file:///tmp/1.png" }, "metadata image"},
+		{"external link", func(m *models.Metadata) { m.ExternalLink = "https://nftrainbow.xyz" }, ""},
+		{"bad external link", func(m *models.Metadata) { m.ExternalLink = "nftrainbow.xyz" }, "metadata external_link"},
+		{"bad animation url", func(m *models.Metadata) { m.AnimationUrl = "ftp://host/1.mp4" }, "metadata animation_url"},
+		{"background colour", func(m *models.Metadata) { m.BackgroundColor = "FFaa00" }, ""},
+		{"background colour with hash", func(m *models.Metadata) { m.BackgroundColor = "#ffaa00" }, ""},
+		{"short background colour", func(m *models.Metadata) { m.BackgroundColor = "#fa0" }, "metadata background_color"},
+		{"named background colour", func(m *models.Metadata) { m.BackgroundColor = "orange" }, "metadata background_color"},
+		{"attribute", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Colour", Value: "Red"}}
+		}, ""},
+		{"attribute without value", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Colour"}}
+		}, `metadata attribute "Colour" has no value`},
+		{"number attributes", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{
+				{TraitType: "Level", DisplayType: "number", Value: "5"},
+				{TraitType: "Speed", DisplayType: "boost_number", Value: "-1.5"},
+				{TraitType: "Luck", DisplayType: "boost_percentage", Value: "10"},
+			}
+		}, ""},
+		{"number attribute not a number", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Level", DisplayType: "number", Value: "five"}}
+		}, "is not a number"},
+		{"date attribute", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Birthday", DisplayType: "date", Value: "1546360800"}}
+		}, ""},
+		{"date attribute not a timestamp", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Birthday", DisplayType: "date", Value: "2019-01-01"}}
+		}, "is not a unix timestamp"},
+		{"unknown display type", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{TraitType: "Level", DisplayType: "stars", Value: "5"}}
+		}, `unknown display_type "stars"`},
+		{"display type without trait type", func(m *models.Metadata) {
+			m.Attributes = []models.Attributes{{DisplayType: "number", Value: "5"}}
+		}, "needs a trait_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := validTestMetadata()
+			tt.modify(metadata)
+			err := ValidateMetadata(metadata)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("ValidateMetadata error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ValidateMetadata error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestValidateMetadataNormalizesBackgroundColor(t *testing.T) {
+	metadata := validTestMetadata()
+	metadata.BackgroundColor = "#ffaa00"
+	if err := ValidateMetadata(metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.BackgroundColor != "ffaa00" {
+		t.Errorf("BackgroundColor = %q, want ffaa00", metadata.BackgroundColor)
+	}
+}
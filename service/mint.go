@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/nft-rainbow/discordBot/models"
+	"github.com/nft-rainbow/discordBot/utils"
 	"github.com/spf13/viper"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -36,16 +38,20 @@ func SendEasyMintRequest(token string, dto models.EasyMintMetaDto) (*models.Mint
 	if tmp.ErrMessage != "" {
 		return nil, errors.New(tmp.ErrMessage)
 	}
-	id, err := getTokenId(tmp.ID, token)
+	task, err := getMintTask(tmp.ID, token)
 	if err != nil {
 		return nil, err
 	}
 
+	explorer := utils.ExplorerByChain(dto.Chain)
 	res := &models.MintResp{
 		UserAddress: dto.MintToAddress,
 		Contract: viper.GetString("easyMint.contract"),
-		NFTAddress: viper.GetString("easyMint.mintRespPrefix") + viper.GetString("easyMint.contract") + "/" + id,
-		TokenID: id,
+		NFTAddress: explorer.NFT(viper.GetString("easyMint.contract"), task.TokenId),
+		Chain: dto.Chain,
+		TokenID: task.TokenId,
+		TxHash: task.Hash,
+		TxURL: explorer.Tx(task.Hash),
 		Time: tmp.BaseModel.CreatedAt.String(),
 	}
 
@@ -78,16 +84,20 @@ func SendCustomMintRequest(token string, dto models.CustomMintDto) (*models.Mint
 		return nil, errors.New(tmp.ErrMessage)
 	}
 
-	id, err := getTokenId(tmp.ID, token)
+	task, err := getMintTask(tmp.ID, token)
 	if err != nil {
 		return nil, err
 	}
 
+	explorer := utils.ExplorerByChain(dto.Chain)
 	res := &models.MintResp{
 		UserAddress: dto.MintToAddress,
-		NFTAddress: viper.GetString("customMint.mintRespPrefix") +  dto.ContractAddress + "/" + id,
+		NFTAddress: explorer.NFT(dto.ContractAddress, task.TokenId),
+		Chain: dto.Chain,
 		Contract: dto.ContractAddress,
-		TokenID: id,
+		TokenID: task.TokenId,
+		TxHash: task.Hash,
+		TxURL: explorer.Tx(task.Hash),
 		Time: tmp.BaseModel.CreatedAt.String(),
 	}
 
@@ -95,16 +105,26 @@ func SendCustomMintRequest(token string, dto models.CustomMintDto) (*models.Mint
 	return res, nil
 }
 
-func CreateMetadata(token, fileUrl, name, description string) (string, error) {
-	metadata := models.Metadata{
-		Name: name,
-		Description: description,
-		Image: fileUrl,
+// CreateMetadata validates the metadata and stores it on NFTRainbow, returning its uri.
+func CreateMetadata(token string, metadata models.Metadata) (string, error) {
+	res, err := CreateMetadataInfo(token, metadata)
+	if err != nil {
+		return "", err
+	}
+	return res.MetadataURI, nil
+}
+
+// CreateMetadataInfo creates the metadata like CreateMetadata, returning its id along with the uri so it can be
+// updated later.
+func CreateMetadataInfo(token string, metadata models.Metadata) (*models.CreateMetadataResponse, error) {
+	err := ValidateMetadata(&metadata)
+	if err != nil {
+		return nil, err
 	}
 
 	b, err := json.Marshal(metadata)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	fmt.Println("Start to create metadata")
 	req, _ := http.NewRequest("POST", viper.GetString("host") + "v1/metadata/", bytes.NewBuffer(b))
@@ -112,27 +132,124 @@ func CreateMetadata(token, fileUrl, name, description string) (string, error) {
 	req.Header.Add("Authorization", "Bearer " + token)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		panic(err)
-		return  "", err
+		return  nil, err
 	}
+	defer resp.Body.Close()
 
 	var tmp models.CreateMetadataResponse
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	err = json.Unmarshal(content, &tmp)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if tmp.Message != "" {
-		return "", errors.New(tmp.Message)
+		return nil, errors.New(tmp.Message)
+	}
+
+	return &tmp, nil
+}
+
+// UpdateMetadata validates the metadata and replaces the content of the metadata with the id. Its uri does not
+// change, so the tokens minted with it show the new content.
+func UpdateMetadata(token, id string, metadata models.Metadata) error {
+	err := ValidateMetadata(&metadata)
+	if err != nil {
+		return err
 	}
 
-	return tmp.MetadataURI, nil
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Start to update metadata")
+	req, err := http.NewRequest("PUT", viper.GetString("host") + "v1/metadata/" + url.PathEscape(id), bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer " + token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	t := make(map[string]interface{})
+	err = json.Unmarshal(content, &t)
+	if err != nil {
+		return err
+	}
+	if t["code"] != nil {
+		return fmt.Errorf("%v", t["message"])
+	}
+	return nil
+}
+
+// GetMintList returns one page of the mint tasks created by the app.
+func GetMintList(token string, page, size int) (*models.MintList, error) {
+	url := fmt.Sprintf("%sv1/mints/?page=%d&size=%d", viper.GetString("host"), page, size)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer " + token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	t := make(map[string]interface{})
+	err = json.Unmarshal(content, &t)
+	if err != nil {
+		return nil, err
+	}
+	if t["code"] != nil {
+		return nil, errors.New(t["message"].(string))
+	}
+
+	var list models.MintList
+	err = json.Unmarshal(content, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetMetadata fetches the metadata json a token uri points to.
+func GetMetadata(uri string) (*models.Metadata, error) {
+	resp, err := http.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var metadata models.Metadata
+	err = json.Unmarshal(content, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
 }
 
-func getTokenId(id uint, token string) (string, error) {
+// getMintTask polls the mint task until the token is minted.
+func getMintTask(id uint, token string) (*models.MintTask, error) {
 	t := models.MintTask{}
 	fmt.Println("Start to get token id")
 	for t.TokenId == "" && t.Status != 1{
@@ -144,21 +261,21 @@ func getTokenId(id uint, token string) (string, error) {
 		req.Header.Add("Authorization", "Bearer " + token)
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		content, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		err = json.Unmarshal(content, &t)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if t.Error != "" {
-			return "", errors.New(t.Error)
+			return nil, errors.New(t.Error)
 		}
 		time.Sleep(10 * time.Second)
 	}
-	return t.TokenId, nil
+	return &t, nil
 }
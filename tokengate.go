@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math/big"
+	"time"
+
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/chain"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// tokenGateRule grants the role to the holders of the contract. With a token id an ERC721 rule requires owning that
+// token and an ERC1155 rule holding minBalance of it, without one the ERC721 balance must reach minBalance.
+type tokenGateRule struct {
+	Contract   string `mapstructure:"contract"`
+	Type       string `mapstructure:"type"`
+	TokenId    string `mapstructure:"tokenId"`
+	MinBalance int64  `mapstructure:"minBalance"`
+	RoleId     string `mapstructure:"roleId"`
+}
+
+// runTokenGate periodically grants or removes the roles of the tokenGate rules to match the holdings of the wallets
+// the members verified with /wallet.
+func runTokenGate(s *discordgo.Session) {
+	if !viper.GetBool("tokenGate.enabled") {
+		return
+	}
+	interval := viper.GetDuration("tokenGate.interval")
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	for {
+		syncTokenGateRoles(s)
+		time.Sleep(interval)
+	}
+}
+
+func syncTokenGateRoles(s *discordgo.Session) {
+	var rules []tokenGateRule
+	if err := viper.UnmarshalKey("tokenGate.rules", &rules); err != nil {
+		log.Printf("Invalid tokenGate.rules: %v", err)
+		return
+	}
+	guildID := viper.GetString("tokenGate.guildId")
+	client, err := chain.NewClient(campaignChain("tokenGate"))
+	if err != nil {
+		log.Printf("Failed to connect to the token gate chain: %v", err)
+		return
+	}
+	// only wallets proven with /wallet count, the addresses users claim with are not proven to be theirs
+	wallets, err := database.GetVerifiedWallets()
+	if err != nil {
+		log.Printf("Failed to load the verified wallets: %v", err)
+		return
+	}
+	claimants, err := database.GetAllUserAddresses()
+	if err != nil {
+		log.Printf("Failed to load the linked wallets: %v", err)
+		return
+	}
+	// claimants without a verified wallet lose the roles they may have been granted for their claim address
+	for userID := range claimants {
+		if _, ok := wallets[userID]; !ok {
+			wallets[userID] = ""
+		}
+	}
+
+	for userID, address := range wallets {
+		member, err := s.GuildMember(guildID, userID)
+		if err != nil {
+			// the user left the guild or never joined it
+			continue
+		}
+		hasRole := make(map[string]bool)
+		for _, role := range member.Roles {
+			hasRole[role] = true
+		}
+
+		// a role is granted when any of its rules holds, and left untouched when a rule could not be checked
+		qualified := make(map[string]bool)
+		unknown := make(map[string]bool)
+		for _, rule := range rules {
+			if address == "" {
+				continue
+			}
+			ok, err := rule.check(client, address)
+			if err != nil {
+				log.Printf("Failed to check token gate rule %s for %s: %v", rule.Contract, address, err)
+				unknown[rule.RoleId] = true
+				continue
+			}
+			qualified[rule.RoleId] = qualified[rule.RoleId] || ok
+		}
+
+		for _, rule := range rules {
+			role := rule.RoleId
+			switch {
+			case qualified[role] && !hasRole[role]:
+				if err = s.GuildMemberRoleAdd(guildID, userID, role); err != nil {
+					log.Printf("Failed to grant role %s to %s: %v", role, userID, err)
+				}
+				hasRole[role] = true
+			case !qualified[role] && !unknown[role] && hasRole[role]:
+				if err = s.GuildMemberRoleRemove(guildID, userID, role); err != nil {
+					log.Printf("Failed to remove role %s from %s: %v", role, userID, err)
+				}
+				hasRole[role] = false
+			}
+		}
+	}
+}
+
+func (r tokenGateRule) check(client *sdk.Client, address string) (bool, error) {
+	minBalance := big.NewInt(r.MinBalance)
+	if r.MinBalance <= 0 {
+		minBalance = big.NewInt(1)
+	}
+	var tokenId *big.Int
+	if r.TokenId != "" {
+		var ok bool
+		if tokenId, ok = new(big.Int).SetString(r.TokenId, 10); !ok {
+			return false, utils.ErrInvalidTokenId
+		}
+	}
+
+	if r.Type == utils.ERC1155 {
+		if tokenId == nil {
+			return false, utils.ErrInvalidTokenId
+		}
+		balance, err := chain.BalanceOf1155(client, r.Contract, address, tokenId)
+		if err != nil {
+			return false, err
+		}
+		return balance.Cmp(minBalance) >= 0, nil
+	}
+
+	if tokenId != nil {
+		owner, err := chain.OwnerOf(client, r.Contract, tokenId)
+		if errors.Is(err, chain.ErrReverted) {
+			// the token was burned or never minted, nobody holds it
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		holder, err := chain.CommonAddress(address)
+		if err != nil {
+			return false, err
+		}
+		return owner == holder, nil
+	}
+	balance, err := chain.BalanceOf(client, r.Contract, address)
+	if err != nil {
+		return false, err
+	}
+	return balance.Cmp(minBalance) >= 0, nil
+}
@@ -13,8 +13,10 @@ const (
 )
 
 const (
-	CONFLUX_MAINNET_ID ChainID = 1029
-	CONFLUX_TEST_ID    ChainID = 1
+	CONFLUX_MAINNET_ID        ChainID = 1029
+	CONFLUX_TEST_ID           ChainID = 1
+	CONFLUX_ESPACE_MAINNET_ID ChainID = 1030
+	CONFLUX_ESPACE_TEST_ID    ChainID = 71
 )
 
 // contract types
@@ -29,6 +31,8 @@ const ERC1155 = "erc1155"
 
 const CONFLUX_TEST = "conflux_test"
 const CONFLUX = "conflux"
+const CONFLUX_ESPACE_TEST = "conflux_espace_test"
+const CONFLUX_ESPACE = "conflux_espace"
 
 func ChainInfoByName(name string) (ChainType, ChainID, error) {
 	switch name {
@@ -36,11 +40,30 @@ func ChainInfoByName(name string) (ChainType, ChainID, error) {
 		return CHAIN_TYPE_CFX, 1, nil
 	case CONFLUX:
 		return CHAIN_TYPE_CFX, 1029, nil
+	case CONFLUX_ESPACE_TEST:
+		return CHAIN_TYPE_ETH, CONFLUX_ESPACE_TEST_ID, nil
+	case CONFLUX_ESPACE:
+		return CHAIN_TYPE_ETH, CONFLUX_ESPACE_MAINNET_ID, nil
 	default:
 		return 0, 0, fmt.Errorf("unknown chain name: %s", name)
 	}
 }
 
+func ChainNameByID(chainId ChainID) (string, error) {
+	switch chainId {
+	case CONFLUX_TEST_ID:
+		return CONFLUX_TEST, nil
+	case CONFLUX_MAINNET_ID:
+		return CONFLUX, nil
+	case CONFLUX_ESPACE_TEST_ID:
+		return CONFLUX_ESPACE_TEST, nil
+	case CONFLUX_ESPACE_MAINNET_ID:
+		return CONFLUX_ESPACE, nil
+	default:
+		return "", fmt.Errorf("unknown chain id: %d", chainId)
+	}
+}
+
 func ContractTypeByName(name string) (ContractType, error) {
 	switch name {
 	case ERC721:
@@ -0,0 +1,14 @@
+package utils
+
+import "github.com/bwmarrin/discordgo"
+
+// SendDM sends the message to the direct message channel of the user, which fails when the user does not accept
+// direct messages from the members of the server.
+func SendDM(s *discordgo.Session, userID string, data *discordgo.MessageSend) error {
+	channel, err := s.UserChannelCreate(userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.ChannelMessageSendComplex(channel.ID, data)
+	return err
+}
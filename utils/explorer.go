@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Explorer builds the scan links of a chain. The zero value belongs to an unknown chain and builds empty links.
+type Explorer struct {
+	BaseURL string
+	// TxPath is the path segment of transaction pages, "transaction" on ConfluxScan and "tx" on EVM scans.
+	TxPath string
+}
+
+var explorers = map[string]Explorer{
+	CONFLUX:             {BaseURL: "https://confluxscan.io", TxPath: "transaction"},
+	CONFLUX_TEST:        {BaseURL: "https://testnet.confluxscan.io", TxPath: "transaction"},
+	CONFLUX_ESPACE:      {BaseURL: "https://evm.confluxscan.io", TxPath: "tx"},
+	CONFLUX_ESPACE_TEST: {BaseURL: "https://evmtestnet.confluxscan.io", TxPath: "tx"},
+}
+
+// ExplorerByChain returns the explorer of the chain, "explorer.<chain>" in the config overrides the default one.
+func ExplorerByChain(chain string) Explorer {
+	explorer := explorers[chain]
+	if url := viper.GetString("explorer." + chain); url != "" {
+		explorer.BaseURL = strings.TrimSuffix(url, "/")
+	}
+	if explorer.TxPath == "" {
+		explorer.TxPath = "tx"
+	}
+	return explorer
+}
+
+func (e Explorer) NFT(contract, tokenId string) string {
+	return e.link("nft", contract, tokenId)
+}
+
+func (e Explorer) Contract(address string) string {
+	return e.link("address", address)
+}
+
+func (e Explorer) Tx(hash string) string {
+	return e.link(e.TxPath, hash)
+}
+
+func (e Explorer) Address(address string) string {
+	return e.link("address", address)
+}
+
+func (e Explorer) link(parts ...string) string {
+	if e.BaseURL == "" {
+		return ""
+	}
+	for _, part := range parts {
+		if part == "" {
+			return ""
+		}
+	}
+	return e.BaseURL + "/" + strings.Join(parts, "/")
+}
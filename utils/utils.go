@@ -3,11 +3,23 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	ErrInvalidAddress = errors.New("invalid address")
+	ErrWrongNetwork   = errors.New("wrong network")
+	ErrInvalidTokenId = errors.New("invalid token id")
+)
+
+var hexAddressPattern = regexp.MustCompile(`^0[xX][0-9a-fA-F]{40}$`)
 
+// CheckCfxAddress parses a base32 address, with or without the type segment, or a hex address which is converted to
+// the base32 address of the chain's network.
 func CheckCfxAddress(chain string, addr string) (*cfxaddress.Address, error) {
 	chainType, chainId, err := ChainInfoByName(chain)
 	if err != nil {
@@ -16,13 +28,87 @@ func CheckCfxAddress(chain string, addr string) (*cfxaddress.Address, error) {
 	if chainType != CHAIN_TYPE_CFX {
 		return nil, errors.New("not cfx chain")
 	}
+	addr = strings.TrimSpace(addr)
+	if hexAddressPattern.MatchString(addr) {
+		addrItem, err := cfxaddress.NewFromHex(addr, uint32(chainId))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+		}
+		// other hex addresses, e.g. the ones of MetaMask, have no private key in the core space
+		if t := addrItem.GetAddressType(); t != cfxaddress.AddressTypeUser && t != cfxaddress.AddressTypeContract {
+			return nil, fmt.Errorf("%w: %s is not a conflux core space address", ErrInvalidAddress, addr)
+		}
+		return &addrItem, nil
+	}
 	addrItem, err := cfxaddress.NewFromBase32(addr)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
 	}
 	if addrItem.GetNetworkID() != uint32(chainId) {
-		return nil, fmt.Errorf("invalid conflux network address, want %v, got %v", addrItem.GetNetworkID(), uint32(chainId))
+		return nil, fmt.Errorf("%w: invalid conflux network address, want %v, got %v", ErrWrongNetwork, uint32(chainId), addrItem.GetNetworkID())
 	}
 	return &addrItem, nil
 }
 
+var base32AddressPattern = regexp.MustCompile(`(?i)^(cfx|cfxtest|net\d+):`)
+
+// CheckEthAddress validates a hex address of an EVM chain. Mixed case addresses must carry a valid EIP-55 checksum.
+func CheckEthAddress(chain string, addr string) (*common.Address, error) {
+	chainType, _, err := ChainInfoByName(chain)
+	if err != nil {
+		return nil, err
+	}
+	if chainType != CHAIN_TYPE_ETH {
+		return nil, errors.New("not eth chain")
+	}
+	addr = strings.TrimSpace(addr)
+	if base32AddressPattern.MatchString(addr) {
+		return nil, fmt.Errorf("%w: %s is a conflux core space address", ErrWrongNetwork, addr)
+	}
+	if !hexAddressPattern.MatchString(addr) {
+		return nil, fmt.Errorf("%w: %s is not a hex address", ErrInvalidAddress, addr)
+	}
+	// the checksum is computed on the body, a 0X prefix is as valid as 0x
+	addr = "0x" + addr[2:]
+	addrItem := common.HexToAddress(addr)
+	body := addr[2:]
+	if strings.ToLower(body) != body && strings.ToUpper(body) != body && addrItem.Hex() != addr {
+		return nil, fmt.Errorf("%w: invalid checksum of %s", ErrInvalidAddress, addr)
+	}
+	return &addrItem, nil
+}
+
+// NormalizeAddress returns the canonical form of the address on the chain, which is the one stored and compared:
+// the short base32 address for conflux and the EIP-55 checksummed address for EVM chains.
+func NormalizeAddress(chain string, addr string) (string, error) {
+	chainType, _, err := ChainInfoByName(chain)
+	if err != nil {
+		return "", err
+	}
+	if chainType == CHAIN_TYPE_ETH {
+		addrItem, err := CheckEthAddress(chain, addr)
+		if err != nil {
+			return "", err
+		}
+		return addrItem.Hex(), nil
+	}
+	addrItem, err := CheckCfxAddress(chain, addr)
+	if err != nil {
+		return "", err
+	}
+	return addrItem.String(), nil
+}
+
+// MaskAddress keeps the network prefix and a few characters of each end, e.g. cfxtest:aak...xyz.
+func MaskAddress(addr string) string {
+	prefix, body := "", addr
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		prefix, body = addr[:i+1], addr[i+1:]
+	} else if strings.HasPrefix(addr, "0x") {
+		prefix, body = "0x", addr[2:]
+	}
+	if len(body) <= 8 {
+		return addr
+	}
+	return prefix + body[:3] + "..." + body[len(body)-3:]
+}
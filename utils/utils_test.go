@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain string
+		addr  string
+		want  string
+		err   error
+	}{
+		{"base32", CONFLUX_TEST, "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", nil},
+		{"verbose to short", CONFLUX_TEST, "CFXTEST:TYPE.USER:AATP533CG7D0AGBD87KZ48NJ1MPNKCA8BE1RZ695J4", "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", nil},
+		{"upper case", CONFLUX_TEST, "CFXTEST:AATP533CG7D0AGBD87KZ48NJ1MPNKCA8BE1RZ695J4", "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", nil},
+		{"surrounding spaces", CONFLUX_TEST, "  cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4 ", "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", nil},
+		{"hex user to base32", CONFLUX_TEST, "0x1ecde7223747601823f7535d7968ba98b4881e09", "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", nil},
+		{"hex user to mainnet base32", CONFLUX, "0x1ECDE7223747601823F7535D7968BA98B4881E09", "cfx:aatp533cg7d0agbd87kz48nj1mpnkca8be7ggp3vpu", nil},
+		{"hex contract to base32", CONFLUX_TEST, "0x8ecde7223747601823f7535d7968ba98b4881e09", "cfxtest:achp533cg7d0agbd87kz48nj1mpnkca8been2ac81k", nil},
+		{"hex builtin", CONFLUX_TEST, "0x0888000000000000000000000000000000000002", "", ErrInvalidAddress},
+		{"metamask hex", CONFLUX_TEST, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "", ErrInvalidAddress},
+		{"wrong network", CONFLUX, "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", "", ErrWrongNetwork},
+		{"bad checksum", CONFLUX_TEST, "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j5", "", ErrInvalidAddress},
+		{"garbage", CONFLUX_TEST, "not an address", "", ErrInvalidAddress},
+		{"espace lower case", CONFLUX_ESPACE, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"espace upper case", CONFLUX_ESPACE, "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"espace checksum", CONFLUX_ESPACE, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"espace checksum with 0X", CONFLUX_ESPACE, "0X5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"espace bad checksum", CONFLUX_ESPACE, "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "", ErrInvalidAddress},
+		{"espace bad checksum with 0X", CONFLUX_ESPACE, "0X5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "", ErrInvalidAddress},
+		{"espace short", CONFLUX_ESPACE, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", "", ErrInvalidAddress},
+		{"espace base32", CONFLUX_ESPACE_TEST, "cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", "", ErrWrongNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAddress(tt.chain, tt.addr)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("NormalizeAddress(%q) error = %v, want %v", tt.addr, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeAddress(%q) error = %v", tt.addr, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAddress(%q) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddressUnknownChain(t *testing.T) {
+	if _, err := NormalizeAddress("ethereum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"); err == nil {
+		t.Error("NormalizeAddress accepted an unknown chain")
+	}
+}
+
+func TestMaskAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"cfxtest:aatp533cg7d0agbd87kz48nj1mpnkca8be1rz695j4", "cfxtest:aat...5j4"},
+		{"CFXTEST:TYPE.USER:AATP533CG7D0AGBD87KZ48NJ1MPNKCA8BE1RZ695J4", "CFXTEST:TYPE.USER:AAT...5J4"},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aA...Aed"},
+		{"short", "short"},
+		{"cfx:abcdefgh", "cfx:abcdefgh"},
+	}
+	for _, tt := range tests {
+		if got := MaskAddress(tt.addr); got != tt.want {
+			t.Errorf("MaskAddress(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
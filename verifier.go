@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/chain"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/utils"
+	"github.com/spf13/viper"
+)
+
+// runMintVerifier periodically confirms the recorded mints on chain, a mint only becomes final once its transaction
+// minted the token to the claimant and is verifier.confirmations epochs deep.
+func runMintVerifier(s *discordgo.Session) {
+	if !viper.GetBool("verifier.enabled") {
+		return
+	}
+	interval := viper.GetDuration("verifier.interval")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	for {
+		verifyMints(s)
+		time.Sleep(interval)
+	}
+}
+
+func verifyMints(s *discordgo.Session) {
+	all, err := database.GetAllMintRecords()
+	if err != nil {
+		log.Printf("Failed to load mint records: %v", err)
+		return
+	}
+	confirmations := viper.GetUint64("verifier.confirmations")
+
+	for address, records := range all {
+		for _, record := range records {
+			if record.Final || record.VerifyError != "" || record.TxHash == "" {
+				continue
+			}
+			// only the conflux core space has an rpc client so far
+			if chainType, _, err := utils.ChainInfoByName(record.Chain); err != nil || chainType != utils.CHAIN_TYPE_CFX {
+				continue
+			}
+			client, err := chain.NewClient(record.Chain)
+			if err != nil {
+				log.Printf("Failed to connect to %s: %v", record.Chain, err)
+				continue
+			}
+
+			final, err := chain.VerifyMint(client, record.TxHash, record.Contract, address, record.TokenID, record.Amount, confirmations)
+			if errors.Is(err, chain.ErrMintMismatch) {
+				record.VerifyError = err.Error()
+				log.Printf("Mint of %s #%s to %s failed verification: %v", record.Contract, record.TokenID, address, err)
+				sendAdminLog(s, &discordgo.MessageEmbed{
+					Type:  discordgo.EmbedTypeRich,
+					Title: "Mint verification failed",
+					Fields: []*discordgo.MessageEmbedField{
+						{Name: "Address", Value: address},
+						{Name: "Token", Value: fmt.Sprintf("%s #%s", record.Contract, record.TokenID)},
+						{Name: "Transaction", Value: orDash(record.TxURL)},
+						{Name: "Error", Value: truncate(err.Error(), 1024)},
+					},
+				})
+			} else if err != nil {
+				log.Printf("Failed to verify mint %s: %v", record.TxHash, err)
+				continue
+			} else if !final {
+				continue
+			}
+			record.Final = final
+			if err = database.UpdateMintRecord(address, record); err != nil {
+				log.Printf("Failed to update mint record of %s: %v", address, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/nft-rainbow/discordBot/chain"
+	"github.com/nft-rainbow/discordBot/database"
+	"github.com/nft-rainbow/discordBot/i18n"
+	"github.com/nft-rainbow/discordBot/utils"
+)
+
+// walletProofTTL is how long the message to sign stays valid.
+const walletProofTTL = 15 * time.Minute
+
+var walletCommand = &discordgo.ApplicationCommand{
+	Name:        "wallet",
+	Description: "Prove you own a wallet, for the token gated roles",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "link",
+			Description: "Get the message to sign with your wallet",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user_address",
+					Description: "The address of your wallet",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "verify",
+			Description: "Send the signature of the message",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "signature",
+					Description: "The signature made by your wallet",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+// handleWallet links a wallet in two steps: link gives the user a message with a nonce, which they sign with their
+// wallet (personal_sign), and verify checks the signature. Every answer is only visible to the user.
+func handleWallet(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	locale := i18n.InteractionLocale(i)
+	sub := i.ApplicationCommandData().Options[0]
+	var content string
+	switch sub.Name {
+	case "link":
+		content = linkWallet(locale, interactionUser(i).ID, sub.Options[0].StringValue())
+	case "verify":
+		content = verifyWallet(locale, interactionUser(i).ID, sub.Options[0].StringValue())
+	default:
+		return
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   uint64(discordgo.MessageFlagsEphemeral),
+		},
+	})
+}
+
+func linkWallet(locale discordgo.Locale, userID, address string) string {
+	address, err := utils.NormalizeAddress(campaignChain("tokenGate"), address)
+	if err != nil {
+		return i18n.T(locale, "error.invalid_address")
+	}
+	message, err := chain.ProofMessage(address, userID)
+	if err != nil {
+		log.Printf("Failed to create a wallet nonce: %v", err)
+		return i18n.T(locale, "error.internal")
+	}
+	err = database.SetPendingWallet(userID, &database.PendingWallet{Address: address, Message: message, CreatedAt: time.Now()})
+	if err != nil {
+		log.Printf("Failed to store the pending wallet of %s: %v", userID, err)
+		return i18n.T(locale, "error.internal")
+	}
+	return i18n.T(locale, "wallet.link", message, int(walletProofTTL.Minutes()))
+}
+
+func verifyWallet(locale discordgo.Locale, userID, signature string) string {
+	pending, err := database.GetPendingWallet(userID)
+	if err != nil {
+		log.Printf("Failed to load the pending wallet of %s: %v", userID, err)
+		return i18n.T(locale, "error.internal")
+	}
+	if pending == nil {
+		return i18n.T(locale, "wallet.no_pending")
+	}
+	err = chain.VerifyProof(pending.Address, pending.Message, signature, pending.CreatedAt, walletProofTTL)
+	if errors.Is(err, chain.ErrProofExpired) {
+		return i18n.T(locale, "wallet.no_pending")
+	}
+	if err != nil {
+		return i18n.T(locale, "wallet.invalid_signature")
+	}
+	if err = database.VerifyWallet(userID, pending.Address); err != nil {
+		log.Printf("Failed to store the wallet of %s: %v", userID, err)
+		return i18n.T(locale, "error.internal")
+	}
+	log.Printf("%s verified the wallet %s", userID, pending.Address)
+	return i18n.T(locale, "wallet.verified", pending.Address)
+}